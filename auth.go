@@ -0,0 +1,80 @@
+package newsapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+//AuthMethod controls where the API key is placed on each request
+type AuthMethod int
+
+const (
+	AuthHeader AuthMethod = iota //X-Api-Key header, the default
+	AuthQuery                    //apiKey query parameter
+	AuthBearer                   //Authorization: Bearer header
+)
+
+//defaultPaths are the paths of each endpoint relative to APIUrl
+var defaultPaths = map[Endpoint]string{
+	EndpointTopHeadlines: "/top-headlines",
+	EndpointEverything:   "/everything",
+	EndpointSources:      "/sources",
+}
+
+//WithAuth sets where the API key is placed on each request
+func WithAuth(m AuthMethod) option {
+	return func(c *Client) {
+		c.auth = m
+	}
+}
+
+//WithBaseURL sets the URL the endpoint paths are appended to, for example a NewsAPI compatible gateway
+func WithBaseURL(u string) option {
+	return func(c *Client) {
+		c.APIUrl = strings.TrimSuffix(u, "/")
+	}
+}
+
+//WithEndpointPath overrides the path used for an endpoint
+//The path is appended to APIUrl unless it is an absolute URL, it may include fixed query parameters
+func WithEndpointPath(e Endpoint, path string) option {
+	return func(c *Client) {
+		if c.paths == nil {
+			c.paths = make(map[Endpoint]string)
+		}
+		c.paths[e] = path
+	}
+}
+
+//endpointURL returns the URL for e ready for query parameters to be appended
+func (c *Client) endpointURL(e Endpoint) string {
+	path, ok := c.paths[e]
+	if !ok {
+		path = defaultPaths[e]
+	}
+	if !strings.Contains(path, "://") {
+		path = c.APIUrl + path
+	}
+	switch {
+	case !strings.Contains(path, "?"):
+		return path + "?"
+	case strings.HasSuffix(path, "?"), strings.HasSuffix(path, "&"):
+		return path
+	default:
+		return path + "&"
+	}
+}
+
+//authorize places key on req according to the Client's AuthMethod
+func (c *Client) authorize(req *http.Request, key Secret) {
+	switch c.auth {
+	case AuthQuery:
+		q := req.URL.Query()
+		q.Set("apiKey", string(key))
+		req.URL.RawQuery = q.Encode()
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+string(key))
+	default:
+		req.Header.Set("X-Api-Key", string(key))
+	}
+}
@@ -0,0 +1,114 @@
+package newsapi
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuthMethods(t *testing.T) {
+	var got *http.Request
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(`{"status":"ok","totalResults":0,"articles":[]}`))
+	}))
+	defer testServer.Close()
+
+	tt := []struct {
+		testName string
+		options  []option
+		header   string
+		value    string
+		query    string
+	}{
+		{"Default", nil, "X-Api-Key", "abc", ""},
+		{"Header", []option{WithAuth(AuthHeader)}, "X-Api-Key", "abc", ""},
+		{"Query", []option{WithAuth(AuthQuery)}, "", "", "abc"},
+		{"Bearer", []option{WithAuth(AuthBearer)}, "Authorization", "Bearer abc", ""},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			options := append(v.options, WithBaseURL(testServer.URL+"/"), WithMiddleware(LoggingMiddleware(log.New(&buf, "", 0))))
+			c := New("abc", options...)
+			a, err := c.GetTopHeadlines(parameters{"q": "test"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.URL.Path != "/top-headlines" || got.URL.Query().Get("q") != "test" {
+				t.Fatalf("Unexpected request URL %v", got.URL)
+			}
+			if v.header != "" && got.Header.Get(v.header) != v.value {
+				t.Fatalf("Expected header %v to be '%v' got '%v'", v.header, v.value, got.Header.Get(v.header))
+			}
+			if v.header != "X-Api-Key" && got.Header.Get("X-Api-Key") != "" {
+				t.Fatal("Expected no X-Api-Key header")
+			}
+			if got.URL.Query().Get("apiKey") != v.query {
+				t.Fatalf("Expected apiKey query parameter '%v' got '%v'", v.query, got.URL.Query().Get("apiKey"))
+			}
+			if strings.Contains(a.Response.URL, "abc") || strings.Contains(buf.String(), "abc") {
+				t.Fatalf("Key leaked in '%v' or '%v'", a.Response.URL, buf.String())
+			}
+		})
+	}
+}
+
+func TestQueryAuthErrorRedacted(t *testing.T) {
+	c := New(testSecret, WithAuth(AuthQuery), WithBaseURL("http://127.0.0.1:1"))
+	_, err := c.GetEverything(parameters{"q": "test"})
+	if _, ok := err.(*TransportError); !ok {
+		t.Fatalf("Expected TransportError got %T", err)
+	}
+	if strings.Contains(err.Error(), testSecret) || !strings.Contains(err.Error(), "apiKey=REDACTED") {
+		t.Fatalf("Expected key to be redacted from '%v'", err)
+	}
+}
+
+func TestEndpointPaths(t *testing.T) {
+	var got string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Path + "?" + r.URL.RawQuery
+		if strings.Contains(r.URL.Path, "sources") {
+			w.Write([]byte(`{"status":"ok","sources":[]}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ok","totalResults":0,"articles":[]}`))
+	}))
+	defer testServer.Close()
+
+	tt := []struct {
+		testName string
+		options  []option
+		call     func(c *Client) error
+		expected string
+	}{
+		{"Default headlines", nil, func(c *Client) error { _, err := c.GetTopHeadlines(parameters{"q": "a"}); return err }, "/v2/top-headlines?q=a"},
+		{"Default everything", nil, func(c *Client) error { _, err := c.GetEverything(parameters{"q": "a"}); return err }, "/v2/everything?q=a"},
+		{"Default sources", nil, func(c *Client) error { _, err := c.GetSources(parameters{}); return err }, "/v2/sources?"},
+		{"Override", []option{WithEndpointPath(EndpointEverything, "/search")}, func(c *Client) error { _, err := c.GetEverything(parameters{"q": "a"}); return err }, "/v2/search?q=a"},
+		{"Override with query", []option{WithEndpointPath(EndpointTopHeadlines, "/news/top?tenant=x")}, func(c *Client) error { _, err := c.GetTopHeadlines(parameters{"q": "a"}); return err }, "/v2/news/top?tenant=x&q=a"},
+		{"Override stream", []option{WithEndpointPath(EndpointTopHeadlines, "/top")}, func(c *Client) error {
+			_, err := c.StreamTopHeadlines(parameters{"q": "a"}, func(Article) error { return nil })
+			return err
+		}, "/v2/top?q=a"},
+		{"Absolute override", []option{WithEndpointPath(EndpointSources, testServer.URL+"/cache/sources")}, func(c *Client) error { _, err := c.GetSources(parameters{}); return err }, "/cache/sources?"},
+		{"Check key", []option{WithEndpointPath(EndpointSources, "/src")}, func(c *Client) error { return c.CheckKey(context.Background()).Err }, "/v2/src?" + checkKeyQuery},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			options := append([]option{WithBaseURL(testServer.URL + "/v2")}, v.options...)
+			c := New("abc", options...)
+			if err := v.call(c); err != nil {
+				t.Fatal(err)
+			}
+			if got != v.expected {
+				t.Fatalf("Expected request to '%v' got '%v'", v.expected, got)
+			}
+		})
+	}
+}
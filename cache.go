@@ -0,0 +1,87 @@
+package newsapi
+
+import (
+	"sync"
+	"time"
+)
+
+//maxCacheEntries is the most replies a Client keeps in its cache
+const maxCacheEntries = 1000
+
+//cacheEntry is a successful reply kept until expires
+type cacheEntry struct {
+	body    []byte
+	res     *Response
+	expires time.Time
+}
+
+//cache keeps successful replies in memory so repeated requests don't use quota
+type cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+//WithCache makes the Client keep successful replies in memory for ttl and answer repeated requests from them
+//Cached replies have Response.FromCache set, streamed requests aren't cached
+func WithCache(ttl time.Duration) option {
+	return func(c *Client) {
+		c.cache = &cache{ttl: ttl, entries: make(map[string]cacheEntry), now: time.Now}
+	}
+}
+
+//ClearCache removes every reply from the Client's cache
+func (c *Client) ClearCache() {
+	if c.cache == nil {
+		return
+	}
+	c.cache.mu.Lock()
+	c.cache.entries = make(map[string]cacheEntry)
+	c.cache.mu.Unlock()
+}
+
+//get returns the cached reply for key if it hasn't expired
+func (c *cache) get(key string) ([]byte, *Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, nil, false
+	}
+	r := *e.res
+	r.FromCache = true
+	r.Shared = false
+	r.Latency = 0
+	return e.body, &r, true
+}
+
+//set caches the reply for key, making room by dropping expired replies then the one closest to expiring
+func (c *cache) set(key string, body []byte, res *Response) {
+	if res == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxCacheEntries {
+		oldest := ""
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+				continue
+			}
+			if oldest == "" || e.expires.Before(c.entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = cacheEntry{body: body, res: res, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,99 @@
+package newsapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	d, err := ioutil.ReadFile("testdata/topheadlines_sucess.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("q") == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","code":"parameterInvalid","message":"Invalid"}`))
+			return
+		}
+		w.Write(d)
+	}))
+	defer testServer.Close()
+
+	now := time.Now()
+	c := New("abc", WithCache(time.Minute), WithPlan(DeveloperPlan))
+	c.APIUrl = testServer.URL
+	c.cache.now = func() time.Time { return now }
+
+	tt := []struct {
+		testName  string
+		q         string
+		advance   time.Duration
+		calls     int
+		fromCache bool
+		err       bool
+	}{
+		{"First request", "a", 0, 1, false, false},
+		{"Cached", "a", 0, 1, true, false},
+		{"Different query", "b", 0, 2, false, false},
+		{"Still fresh", "a", 59 * time.Second, 2, true, false},
+		{"Expired", "a", time.Second, 3, false, false},
+		{"Error", "fail", 0, 4, false, true},
+		{"Errors aren't cached", "fail", 0, 5, false, true},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			now = now.Add(v.advance)
+			o, err := c.GetTopHeadlines(parameters{"q": v.q})
+			if (err != nil) != v.err {
+				t.Fatalf("Expected error %v got %v", v.err, err)
+			}
+			if calls != v.calls {
+				t.Fatalf("Expected %v requests got %v", v.calls, calls)
+			}
+			if o.Response.FromCache != v.fromCache {
+				t.Fatalf("Expected FromCache %v got %v", v.fromCache, o.Response.FromCache)
+			}
+			if !v.err && len(o.Articles) == 0 {
+				t.Fatal("Expected articles")
+			}
+		})
+	}
+	if n, _ := c.QuotaRemaining(); n != DeveloperPlan.Quota-5 {
+		t.Fatalf("Expected cached replies not to use quota got %v remaining", n)
+	}
+	c.ClearCache()
+	if _, err := c.GetTopHeadlines(parameters{"q": "a"}); err != nil || calls != 6 {
+		t.Fatalf("Expected request after clearing cache got %v requests %v", calls, err)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	now := time.Now()
+	c := &cache{ttl: time.Minute, entries: make(map[string]cacheEntry), now: func() time.Time { return now }}
+	for i := 0; i < maxCacheEntries; i++ {
+		c.set(fmt.Sprint(i), nil, &Response{})
+		now = now.Add(time.Millisecond)
+	}
+	c.set("new", nil, &Response{})
+	if len(c.entries) != maxCacheEntries {
+		t.Fatalf("Expected %v entries got %v", maxCacheEntries, len(c.entries))
+	}
+	if _, _, ok := c.get("0"); ok {
+		t.Fatal("Expected oldest entry to be evicted")
+	}
+	if _, _, ok := c.get("new"); !ok {
+		t.Fatal("Expected new entry to be cached")
+	}
+	now = now.Add(time.Hour)
+	c.set("later", nil, &Response{})
+	if len(c.entries) != 1 {
+		t.Fatalf("Expected expired entries to be dropped got %v entries", len(c.entries))
+	}
+}
@@ -0,0 +1,122 @@
+package newsapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//KeyStatus is the outcome of checking an API key
+type KeyStatus int
+
+const (
+	//KeyUnknown means NewsAPI replied with an error that doesn't describe the key
+	KeyUnknown KeyStatus = iota
+	//KeyValid means the key was accepted
+	KeyValid
+	//KeyInvalid means the key is missing or NewsAPI doesn't recognise it
+	KeyInvalid
+	//KeyDisabled means the key has been disabled by NewsAPI
+	KeyDisabled
+	//KeyExhausted means the key has no requests left
+	KeyExhausted
+	//KeyRateLimited means the key has made too many requests recently
+	KeyRateLimited
+	//KeyNetworkFailure means NewsAPI couldn't be reached or something in front of it replied instead
+	KeyNetworkFailure
+)
+
+var keyStatusNames = map[KeyStatus]string{
+	KeyUnknown:        "unknown",
+	KeyValid:          "valid",
+	KeyInvalid:        "invalid",
+	KeyDisabled:       "disabled",
+	KeyExhausted:      "exhausted",
+	KeyRateLimited:    "rate limited",
+	KeyNetworkFailure: "network failure",
+}
+
+func (s KeyStatus) String() string {
+	return keyStatusNames[s]
+}
+
+//checkKeyQuery is the cheapest request that NewsAPI will accept
+const checkKeyQuery = "language=en"
+
+//KeyReport contains the result of CheckKey
+type KeyReport struct {
+	Status     KeyStatus
+	StatusCode int    //HTTP status code, zero when no reply was received
+	Code       string //NewsAPI error code
+	Message    string //NewsAPI error message
+	Latency    time.Duration
+	RateLimit  http.Header //Any X-RateLimit-* and Retry-After headers that were sent
+	Err        error       //The error returned by the request, nil when the key is valid
+}
+
+//CheckKey sends the cheapest valid request using APIKey and classifies the outcome
+//The request isn't counted against the plan quota, only APIKey is checked and any KeyPool is ignored
+//With WithDryRun nothing is sent, the report has status KeyUnknown and a DryRunError
+func (c *Client) CheckKey(ctx context.Context) KeyReport {
+	if c.APIKey == "" {
+		return KeyReport{Status: KeyInvalid, Code: "apiKeyMissing", Message: "Expected API key got nothing"}
+	}
+	u := c.endpointURL(EndpointSources) + checkKeyQuery
+	if c.dryRun {
+		return KeyReport{Status: KeyUnknown, RateLimit: make(http.Header), Err: c.dryRunError(u)}
+	}
+	_, res, err := c.send(ctx, u, c.APIKey)
+	r := KeyReport{
+		RateLimit: make(http.Header),
+		Err:       err,
+	}
+	if res != nil {
+		r.Latency = res.Latency
+		r.RateLimit = rateLimitHeaders(res.Header)
+	}
+	switch e := err.(type) {
+	case nil:
+		r.Status = KeyValid
+		r.StatusCode = http.StatusOK
+	case *APIError:
+		r.StatusCode = e.StatusCode
+		r.Code = e.Code
+		r.Message = e.Message
+		r.Status = keyStatusForCode(e.Code)
+	case *UpstreamError:
+		r.StatusCode = e.StatusCode
+		r.Status = KeyNetworkFailure
+		r.Message = err.Error()
+	default:
+		r.Status = KeyNetworkFailure
+		r.Message = err.Error()
+	}
+	return r
+}
+
+//keyStatusForCode maps a NewsAPI error code to a KeyStatus
+func keyStatusForCode(code string) KeyStatus {
+	switch code {
+	case "apiKeyInvalid", "apiKeyMissing":
+		return KeyInvalid
+	case "apiKeyDisabled":
+		return KeyDisabled
+	case "apiKeyExhausted":
+		return KeyExhausted
+	case "rateLimited":
+		return KeyRateLimited
+	}
+	return KeyUnknown
+}
+
+//rateLimitHeaders returns the rate limit related headers from h
+func rateLimitHeaders(h http.Header) http.Header {
+	r := make(http.Header)
+	for k, v := range h {
+		if strings.HasPrefix(k, "X-Ratelimit-") || k == "Retry-After" {
+			r[k] = v
+		}
+	}
+	return r
+}
@@ -0,0 +1,136 @@
+package newsapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckKey(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sources" || r.URL.RawQuery != checkKeyQuery {
+			t.Errorf("Unexpected request %v", r.URL)
+		}
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		switch r.Header.Get("X-Api-Key") {
+		case "disabled-key":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error","code":"apiKeyDisabled","message":"Your API key has been disabled."}`))
+		case "exhausted-key":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","code":"apiKeyExhausted","message":"Your API key has no more requests available."}`))
+		case "limited-key":
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","code":"rateLimited","message":"You have been rate limited."}`))
+		case "invalid-key":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error","code":"apiKeyInvalid","message":"Your API key is invalid or incorrect."}`))
+		case "odd-key":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status":"error","code":"unexpectedError","message":"This shouldn't happen."}`))
+		default:
+			w.Write([]byte(`{"status":"ok","sources":[]}`))
+		}
+	}))
+	defer testServer.Close()
+
+	tt := []struct {
+		testName       string
+		apiKey         string
+		url            string
+		expectedStatus KeyStatus
+		expectedCode   int
+		retryAfter     string
+	}{
+		{"Valid", "valid-key", testServer.URL, KeyValid, http.StatusOK, ""},
+		{"Invalid", "invalid-key", testServer.URL, KeyInvalid, http.StatusUnauthorized, ""},
+		{"Missing", "", testServer.URL, KeyInvalid, 0, ""},
+		{"Disabled", "disabled-key", testServer.URL, KeyDisabled, http.StatusUnauthorized, ""},
+		{"Exhausted", "exhausted-key", testServer.URL, KeyExhausted, http.StatusTooManyRequests, ""},
+		{"Rate limited", "limited-key", testServer.URL, KeyRateLimited, http.StatusTooManyRequests, "60"},
+		{"Unknown error", "odd-key", testServer.URL, KeyUnknown, http.StatusInternalServerError, ""},
+		{"Network failure", "valid-key", "http://127.0.0.1:1", KeyNetworkFailure, 0, ""},
+	}
+
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New(v.apiKey)
+			c.APIUrl = v.url
+			c.HTTPClient.Timeout = time.Second * 2
+			r := c.CheckKey(context.Background())
+			if r.Status != v.expectedStatus {
+				t.Fatalf("Expected status '%v' got '%v' (%v)", v.expectedStatus, r.Status, r.Message)
+			}
+			if r.StatusCode != v.expectedCode {
+				t.Fatalf("Expected status code %d got %d", v.expectedCode, r.StatusCode)
+			}
+			if (r.Err != nil) == (v.expectedStatus == KeyValid) && v.apiKey != "" {
+				t.Fatalf("Unexpected error value %v", r.Err)
+			}
+			if v.expectedCode != 0 && r.RateLimit.Get("X-RateLimit-Remaining") != "42" {
+				t.Fatalf("Expected rate limit headers got %v", r.RateLimit)
+			}
+			if r.RateLimit.Get("Retry-After") != v.retryAfter {
+				t.Fatalf("Expected Retry-After '%v' got '%v'", v.retryAfter, r.RateLimit.Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestCheckKeyIgnoresQuota(t *testing.T) {
+	testServer := keyServer()
+	defer testServer.Close()
+
+	tt := []struct {
+		testName string
+		spent    bool
+		expected int
+	}{
+		{"Quota left", false, 1},
+		{"Quota spent", true, 0},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New("valid-key", WithPlan(Plan{Name: "Tiny", Quota: 1, QuotaPeriod: time.Hour}))
+			c.APIUrl = testServer.URL
+			if v.spent {
+				c.limiter.take()
+			}
+			if r := c.CheckKey(context.Background()); r.Status != KeyValid {
+				t.Fatalf("Expected a valid key got %v %v", r.Status, r.Err)
+			}
+			if r, _ := c.QuotaRemaining(); r != v.expected {
+				t.Fatalf("Expected %d requests left in the quota got %d", v.expected, r)
+			}
+		})
+	}
+}
+
+func TestKeyStatusString(t *testing.T) {
+	if s := KeyRateLimited.String(); s != "rate limited" {
+		t.Fatalf("Expected 'rate limited' got '%v'", s)
+	}
+}
+
+func TestCheckKeyDryRun(t *testing.T) {
+	var hits int
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`{"status":"ok","sources":[]}`))
+	}))
+	defer testServer.Close()
+
+	c := New("valid-key", WithDryRun())
+	c.APIUrl = testServer.URL
+	r := c.CheckKey(context.Background())
+	dr, ok := r.Err.(*DryRunError)
+	if !ok || r.Status != KeyUnknown {
+		t.Fatalf("Expected unknown status with a DryRunError got %v %v", r.Status, r.Err)
+	}
+	if dr.URL != testServer.URL+"/sources?"+checkKeyQuery || hits != 0 {
+		t.Fatalf("Expected %v not to be sent got %v with %d requests", testServer.URL+"/sources?"+checkKeyQuery, dr.URL, hits)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+func runHeadlines(e *env, args []string) error {
+	fs, g, p := newFlagSet(e, "headlines")
+	headlinesFlags(fs, p)
+	if err := parse(fs, g, args, articleHeader); err != nil {
+		return err
+	}
+	c, err := g.client(e)
+	if err != nil {
+		return err
+	}
+	r, err := c.GetTopHeadlines(p.parameters())
+	if err != nil {
+		return dryRun(e, err)
+	}
+	return g.out.articles(e.stdout, r)
+}
+
+func runEverything(e *env, args []string) error {
+	fs, g, p := newFlagSet(e, "everything")
+	everythingFlags(fs, p)
+	if err := parse(fs, g, args, articleHeader); err != nil {
+		return err
+	}
+	c, err := g.client(e)
+	if err != nil {
+		return err
+	}
+	r, err := c.GetEverything(p.parameters())
+	if err != nil {
+		return dryRun(e, err)
+	}
+	return g.out.articles(e.stdout, r)
+}
+
+func runSources(e *env, args []string) error {
+	fs, g, p := newFlagSet(e, "sources")
+	sourcesFlags(fs, p)
+	if err := parse(fs, g, args, sourceHeader); err != nil {
+		return err
+	}
+	c, err := g.client(e)
+	if err != nil {
+		return err
+	}
+	r, err := c.GetSources(p.parameters())
+	if err != nil {
+		return dryRun(e, err)
+	}
+	return g.out.sources(e.stdout, r)
+}
+
+//headlinesFlags adds a flag for each top headlines parameter
+func headlinesFlags(fs *flag.FlagSet, p *paramFlags) {
+	p.str(fs, "country", "country", "2-letter country code")
+	p.str(fs, "category", "category", "Category of news")
+	p.list(fs, "sources", "sources", "Comma separated source IDs, can't be mixed with -country or -category")
+	p.str(fs, "q", "q", "Keywords or phrase to search for")
+	p.int(fs, "page-size", "pageSize", "Number of results per page")
+	p.int(fs, "page", "page", "Page of results")
+}
+
+//everythingFlags adds a flag for each everything parameter
+func everythingFlags(fs *flag.FlagSet, p *paramFlags) {
+	p.str(fs, "q", "q", "Keywords or phrase to search for")
+	p.list(fs, "sources", "sources", "Comma separated source IDs")
+	p.list(fs, "domains", "domains", "Comma separated domains")
+	p.str(fs, "from", "from", "Oldest article date")
+	p.str(fs, "to", "to", "Newest article date")
+	p.str(fs, "language", "language", "2-letter language code")
+	p.str(fs, "sort-by", "sortBy", "Sort order: relevancy, popularity or publishedAt")
+	p.int(fs, "page-size", "pageSize", "Number of results per page")
+	p.int(fs, "page", "page", "Page of results")
+}
+
+//sourcesFlags adds a flag for each sources parameter
+func sourcesFlags(fs *flag.FlagSet, p *paramFlags) {
+	p.str(fs, "country", "country", "2-letter country code")
+	p.str(fs, "category", "category", "Category of news")
+	p.str(fs, "language", "language", "2-letter language code")
+}
+
+//dryRun prints the request described by a DryRunError and returns any other error unchanged
+func dryRun(e *env, err error) error {
+	var dr *newsapi.DryRunError
+	if !errors.As(err, &dr) {
+		return err
+	}
+	fmt.Fprintln(e.stdout, dr.Method, dr.URL)
+	return nil
+}
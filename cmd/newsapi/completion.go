@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+//completeCommand is the hidden command the completion scripts run to get completions
+const completeCommand = "__complete"
+
+//sourcesCacheAge is how long the sources catalog used for completion is kept before it's fetched again
+const sourcesCacheAge = 24 * time.Hour
+
+//sourcesTimeout is how long completion waits for the sources catalog
+const sourcesTimeout = 5 * time.Second
+
+//completionScripts are the completion scripts for each shell, each passes the words typed after newsapi to __complete
+var completionScripts = map[string]string{
+	"bash": `# bash completion for newsapi, add to ~/.bashrc:
+#   source <(newsapi completion bash)
+_newsapi() {
+    local IFS=$'\n'
+    COMPREPLY=($(newsapi __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _newsapi newsapi
+`,
+	"zsh": `#compdef newsapi
+# zsh completion for newsapi, add to ~/.zshrc:
+#   source <(newsapi completion zsh)
+_newsapi() {
+    local -a candidates
+    candidates=(${(f)"$(newsapi __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    if (( ${#candidates} )); then
+        compadd -- $candidates
+    else
+        _files
+    fi
+}
+compdef _newsapi newsapi
+`,
+	"fish": `# fish completion for newsapi, save to ~/.config/fish/completions/newsapi.fish:
+#   newsapi completion fish > ~/.config/fish/completions/newsapi.fish
+function __newsapi_complete
+    set -l args (commandline -opc)
+    set -e args[1]
+    newsapi __complete $args (commandline -ct) 2>/dev/null
+end
+complete -c newsapi -f -a '(__newsapi_complete)'
+`,
+}
+
+func runCompletion(e *env, args []string) error {
+	if len(args) != 1 {
+		return usageError("Expected bash, zsh or fish, for example newsapi completion bash")
+	}
+	s, ok := completionScripts[args[0]]
+	if !ok {
+		return usageError(fmt.Sprintf("Unsupported shell %v, expected bash, zsh or fish", args[0]))
+	}
+	_, err := io.WriteString(e.stdout, s)
+	return err
+}
+
+//runComplete prints a completion for the last of args on each line, args are the words typed after newsapi
+func runComplete(e *env, args []string) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	cur := args[len(args)-1]
+	for _, c := range complete(e, args[:len(args)-1], cur) {
+		if strings.HasPrefix(c, cur) {
+			fmt.Fprintln(e.stdout, c)
+		}
+	}
+}
+
+//complete returns the candidates for cur, the caller removes those that don't start with cur
+func complete(e *env, typed []string, cur string) []string {
+	if len(typed) == 0 {
+		names := []string{"help"}
+		for _, c := range commands() {
+			names = append(names, c.name)
+		}
+		return names
+	}
+	if i := strings.Index(cur, "="); i > 0 && strings.HasPrefix(cur, "-") {
+		values := flagValues(e, typedGlobals(typed), strings.TrimLeft(cur[:i], "-"), cur[i+1:])
+		for j := range values {
+			values[j] = cur[:i+1] + values[j]
+		}
+		return values
+	}
+	flags := commandFlags(e, typed)
+	if prev := typed[len(typed)-1]; len(typed) > 1 && strings.HasPrefix(prev, "-") && !strings.Contains(prev, "=") {
+		if flags[strings.TrimLeft(prev, "-")] {
+			return flagValues(e, typedGlobals(typed), strings.TrimLeft(prev, "-"), cur)
+		}
+	}
+	if strings.HasPrefix(cur, "-") {
+		dash := "-"
+		if strings.HasPrefix(cur, "--") {
+			dash = "--"
+		}
+		names := make([]string, 0, len(flags))
+		for f := range flags {
+			names = append(names, dash+f)
+		}
+		sort.Strings(names)
+		return names
+	}
+	if pos := leading(typed); len(pos) == len(typed)-1 {
+		return positional(e, typed[0], pos)
+	}
+	return nil
+}
+
+//leading returns the words after the command name up to the first flag
+func leading(typed []string) []string {
+	for i, w := range typed[1:] {
+		if strings.HasPrefix(w, "-") {
+			return typed[1 : i+1]
+		}
+	}
+	return typed[1:]
+}
+
+//positional returns the candidates for the next argument of the command name after the arguments pos
+func positional(e *env, name string, pos []string) []string {
+	switch {
+	case name == "watch" && len(pos) == 0:
+		return []string{"everything", "headlines"}
+	case name == "completion" && len(pos) == 0:
+		return []string{"bash", "fish", "zsh"}
+	case name == "search" && len(pos) == 0:
+		return []string{"add", "list", "run"}
+	case name == "search" && len(pos) == 1 && pos[0] == "run":
+		dir := configDir(e)
+		if dir == "" {
+			return nil
+		}
+		s, err := loadSearches(filepath.Join(dir, "searches.json"))
+		if err != nil {
+			return nil
+		}
+		var names []string
+		for _, ss := range s.Searches {
+			names = append(names, ss.Name)
+		}
+		return names
+	case name == "search" && len(pos) == 2 && pos[0] == "add":
+		return []string{"everything", "headlines", "sources"}
+	}
+	return nil
+}
+
+//commandFlags returns the flags of the command being typed and whether each takes a value
+//The flags are read from the usage the command prints for -h after the arguments typed before any flag
+func commandFlags(e *env, typed []string) map[string]bool {
+	var c *command
+	for _, cmd := range commands() {
+		if cmd.name == typed[0] {
+			c = &cmd
+			break
+		}
+	}
+	flags := make(map[string]bool)
+	if c == nil {
+		return flags
+	}
+	var out bytes.Buffer
+	args := append(append([]string{}, leading(typed)...), "-h")
+	c.run(&env{ctx: e.ctx, stdin: strings.NewReader(""), stdout: &out, stderr: &out, getenv: e.getenv}, args)
+	for _, l := range strings.Split(out.String(), "\n") {
+		if !strings.HasPrefix(l, "  -") {
+			continue
+		}
+		f := strings.Fields(strings.SplitN(l, "\t", 2)[0])
+		flags[f[0][1:]] = len(f) > 1
+	}
+	return flags
+}
+
+//typedGlobals returns the -config and -profile flags already typed, so completions read the same profile the command will
+func typedGlobals(typed []string) *globalFlags {
+	g := &globalFlags{}
+	for i, w := range typed {
+		if !strings.HasPrefix(w, "-") {
+			continue
+		}
+		name, value := strings.TrimLeft(w, "-"), ""
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value = name[:j], name[j+1:]
+		} else if i+1 < len(typed) {
+			value = typed[i+1]
+		}
+		switch name {
+		case "config":
+			g.config = value
+		case "profile":
+			g.profile = value
+		}
+	}
+	return g
+}
+
+//flagValues returns the values the flag name accepts, list flags complete the value after the last comma in cur
+//g holds the -config and -profile flags typed so far
+func flagValues(e *env, g *globalFlags, name, cur string) []string {
+	var values []string
+	list := false
+	switch name {
+	case "country":
+		values = newsapi.Countries()
+	case "category":
+		values = newsapi.Categories()
+	case "language":
+		values = newsapi.Languages()
+	case "sort-by":
+		values = newsapi.SortByOptions()
+	case "format":
+		values = append(values, formats...)
+	case "plan":
+		values = newsapi.PlanNames()
+	case "profile":
+		path := g.config
+		if path == "" {
+			path = newsapi.DefaultConfigPath(e.getenv)
+		}
+		if c, err := newsapi.LoadConfig(path); err == nil {
+			for name := range c.Profiles {
+				values = append(values, name)
+			}
+			sort.Strings(values)
+		}
+	case "sources":
+		values, list = sourceIDs(e, g), true
+	case "fields":
+		values, list = append([]string{}, articleHeader...), true
+		for _, f := range sourceHeader {
+			if index(values, f) < 0 {
+				values = append(values, f)
+			}
+		}
+	}
+	if list {
+		head := cur[:strings.LastIndex(cur, ",")+1]
+		for i := range values {
+			values[i] = head + values[i]
+		}
+	}
+	return values
+}
+
+//sourcesCachePath returns the file the sources catalog is cached in
+func sourcesCachePath(e *env, g *globalFlags) string {
+	dir := cacheDir(e, g)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "sources.json")
+}
+
+//sourceIDs returns the IDs in the cached sources catalog, fetching it again once it's older than sourcesCacheAge
+//A stale catalog is used when it can't be fetched
+func sourceIDs(e *env, g *globalFlags) []string {
+	path := sourcesCachePath(e, g)
+	if path == "" {
+		return nil
+	}
+	var ids []string
+	fi, err := os.Stat(path)
+	if err == nil {
+		var d []byte
+		if d, err = ioutil.ReadFile(path); err == nil {
+			err = json.Unmarshal(d, &ids)
+		}
+	}
+	if err == nil && now().Sub(fi.ModTime()) < sourcesCacheAge {
+		return ids
+	}
+	fresh, err := fetchSourceIDs(e, g)
+	if err != nil {
+		return ids
+	}
+	if d, err := json.Marshal(fresh); err == nil && os.MkdirAll(filepath.Dir(path), 0700) == nil {
+		ioutil.WriteFile(path, d, 0600)
+	}
+	return fresh
+}
+
+//fetchSourceIDs requests the sources catalog with the key from the environment or the profile chosen by g
+func fetchSourceIDs(e *env, g *globalFlags) ([]string, error) {
+	fg := &globalFlags{config: g.config, profile: g.profile, timeout: sourcesTimeout}
+	c, err := fg.client(e)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.GetSources(map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(r.Source))
+	for i, s := range r.Source {
+		ids[i] = s.ID
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompletionScripts(t *testing.T) {
+	tt := []struct {
+		testName string
+		args     []string
+		code     int
+		stdout   string
+		stderr   string
+	}{
+		{"Bash", []string{"completion", "bash"}, 0, "complete -o default -F _newsapi newsapi", ""},
+		{"Zsh", []string{"completion", "zsh"}, 0, "#compdef newsapi", ""},
+		{"Fish", []string{"completion", "fish"}, 0, "complete -c newsapi -f -a '(__newsapi_complete)'", ""},
+		{"Unknown shell", []string{"completion", "tcsh"}, 2, "", "Unsupported shell tcsh"},
+		{"No shell", []string{"completion"}, 2, "", "Expected bash, zsh or fish"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			stdout, stderr, code := runCmd(v.args, nil)
+			if code != v.code || !strings.Contains(stdout, v.stdout) || !strings.Contains(stderr, v.stderr) {
+				t.Fatalf("Expected %v '%v' '%v' got %v '%v' '%v'", v.code, v.stdout, v.stderr, code, stdout, stderr)
+			}
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s, last := testServer(t)
+	home := t.TempDir()
+	vars := map[string]string{"HOME": home, "NEWSAPI_KEY": "abc"}
+	config := filepath.Join(home, ".config", "newsapi")
+	os.MkdirAll(config, 0700)
+	ioutil.WriteFile(filepath.Join(config, "config.yaml"), []byte("baseURL: "+s.URL+"\n"), 0600)
+	runCmd([]string{"search", "add", "uk-tech", "headlines", "-country", "gb"}, vars)
+
+	tt := []struct {
+		testName string
+		args     []string
+		expected string
+	}{
+		{"Commands", []string{""}, "help\nheadlines\neverything\nsources\nsearch\nwatch\nsnapshot\ndiff\nrepl\ntui\nmock\ncompletion\n"},
+		{"Command prefix", []string{"s"}, "sources\nsearch\nsnapshot\n"},
+		{"Flags", []string{"headlines", "-c"}, "-category\n-config\n-country\n"},
+		{"Double dash flags", []string{"sources", "--l"}, "--language\n"},
+		{"Country", []string{"headlines", "-country", "g"}, "gb\ngr\n"},
+		{"Country equals", []string{"headlines", "-country=g"}, "-country=gb\n-country=gr\n"},
+		{"Category", []string{"sources", "-language", "en", "-category", "s"}, "science\nsports\n"},
+		{"Language", []string{"everything", "-language", "e"}, "en\nes\n"},
+		{"Sort by", []string{"everything", "-sort-by", "p"}, "popularity\npublishedAt\n"},
+		{"Format", []string{"sources", "-format", "n"}, "ndjson\n"},
+		{"Fields list", []string{"headlines", "-fields", "title,sou"}, "title,sourceId\ntitle,sourceName\n"},
+		{"Plan", []string{"repl", "-plan", ""}, "business\ndeveloper\n"},
+		{"Sources", []string{"everything", "-sources", "abc-news,al-j"}, "abc-news,al-jazeera-english\n"},
+		{"Value without completions", []string{"headlines", "-key", ""}, ""},
+		{"After bool flag", []string{"headlines", "-dry-run", "-pa"}, "-page\n-page-size\n"},
+		{"Watch endpoint", []string{"watch", ""}, "everything\nheadlines\n"},
+		{"Watch flags", []string{"watch", "everything", "-i"}, "-interval\n"},
+		{"Search subcommand", []string{"search", "r"}, "run\n"},
+		{"Saved search names", []string{"search", "run", ""}, "uk-tech\n"},
+		{"Search add endpoint", []string{"search", "add", "name", "s"}, "sources\n"},
+		{"Search add flags", []string{"search", "add", "name", "sources", "-l"}, "-language\n"},
+		{"Completion shell", []string{"completion", ""}, "bash\nfish\nzsh\n"},
+		{"No positional after flags", []string{"watch", "-interval", "1m", ""}, ""},
+		{"Unknown command", []string{"nope", "-"}, ""},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			stdout, stderr, code := runCmd(append([]string{completeCommand}, v.args...), vars)
+			if code != 0 || stdout != v.expected {
+				t.Fatalf("Expected '%v' got %v '%v' %v", v.expected, code, stdout, stderr)
+			}
+		})
+	}
+
+	if !strings.HasSuffix(last.URL.Path, "/sources") {
+		t.Fatalf("Expected sources catalog request got %v", last.URL)
+	}
+	cache := filepath.Join(home, ".cache", "newsapi", "sources.json")
+	if _, err := os.Stat(cache); err != nil {
+		t.Fatalf("Expected sources catalog to be cached: %v", err)
+	}
+	last.URL = nil
+	if stdout, _, _ := runCmd([]string{completeCommand, "headlines", "-sources", "bbc-n"}, vars); stdout != "bbc-news\n" || last.URL != nil {
+		t.Fatalf("Expected cached catalog to be used got '%v' %v", stdout, last.URL)
+	}
+
+	old := time.Now().Add(-2 * sourcesCacheAge)
+	os.Chtimes(cache, old, old)
+	ioutil.WriteFile(cache, []byte(`["stale"]`), 0600)
+	os.Chtimes(cache, old, old)
+	if stdout, _, _ := runCmd([]string{completeCommand, "headlines", "-sources", ""}, map[string]string{"HOME": home}); stdout != "stale\n" {
+		t.Fatalf("Expected stale catalog when it can't be fetched got '%v'", stdout)
+	}
+	if stdout, _, _ := runCmd([]string{completeCommand, "headlines", "-sources", "bbc-n"}, vars); stdout != "bbc-news\n" {
+		t.Fatalf("Expected stale catalog to be fetched again got '%v'", stdout)
+	}
+}
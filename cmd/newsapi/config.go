@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/Oliver-Fish/newsapi"
+)
+
+//configDir returns the directory newsapi keeps its files in, or an empty string when there's no home directory
+func configDir(e *env) string {
+	return newsapi.DefaultConfigDir(e.getenv)
+}
+
+//cacheDir returns the directory newsapi caches data in, or an empty string when there's no home directory
+//The cacheDir of the profile chosen by g's -config and -profile flags and NEWSAPI_CACHE_DIR take precedence
+func cacheDir(e *env, g *globalFlags) string {
+	if p, err := loadConfig(e, g.config, g.profile); err == nil && p.CacheDir != "" {
+		return p.CacheDir
+	}
+	return newsapi.DefaultCacheDir(e.getenv)
+}
+
+//loadConfig returns the profile called name from the config file at path with the NEWSAPI_* environment variables applied
+//An empty path reads the default config file, a missing default config file isn't an error
+func loadConfig(e *env, path, name string) (newsapi.Profile, error) {
+	return newsapi.LoadProfile(path, name, e.getenv)
+}
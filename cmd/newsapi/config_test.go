@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyPrecedence(t *testing.T) {
+	s, last := testServer(t)
+	home := t.TempDir()
+	dir := filepath.Join(home, ".config", "newsapi")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte("key: from-config\nbaseURL: "+s.URL+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(home, "other.json")
+	if err := ioutil.WriteFile(other, []byte(`{"key":"from-other","baseURL":"`+s.URL+`"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		testName string
+		args     []string
+		vars     map[string]string
+		expected string
+	}{
+		{"Config file", nil, map[string]string{"HOME": home}, "from-config"},
+		{"XDG config home", nil, map[string]string{"XDG_CONFIG_HOME": filepath.Join(home, ".config")}, "from-config"},
+		{"Explicit config file", []string{"-config", other}, map[string]string{"HOME": home}, "from-other"},
+		{"Environment", nil, map[string]string{"HOME": home, "NEWSAPI_KEY": "from-env"}, "from-env"},
+		{"Flag", []string{"-key", "from-flag"}, map[string]string{"HOME": home, "NEWSAPI_KEY": "from-env"}, "from-flag"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			runCmd(append([]string{"sources"}, v.args...), v.vars)
+			if k := last.Header.Get("X-Api-Key"); k != v.expected {
+				t.Fatalf("Expected key '%v' got '%v'", v.expected, k)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	ioutil.WriteFile(invalid, []byte("{"), 0600)
+
+	tt := []struct {
+		testName string
+		vars     map[string]string
+		path     string
+		err      bool
+	}{
+		{"No home", nil, "", false},
+		{"Missing default", map[string]string{"HOME": dir}, "", false},
+		{"Missing explicit", nil, filepath.Join(dir, "missing.json"), true},
+		{"Invalid", nil, invalid, true},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			e := &env{getenv: func(k string) string { return v.vars[k] }}
+			_, err := loadConfig(e, v.path, "")
+			if (err != nil) != v.err {
+				t.Fatalf("Expected error %v got %v", v.err, err)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	s, last := testServer(t)
+	home := t.TempDir()
+	dir := filepath.Join(home, ".config", "newsapi")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	config := "baseURL: " + s.URL + "\nkey: shared\nprofile: work\nprofiles:\n  work:\n    key: from-work\n    defaults:\n      country: gb\n  home:\n    key: from-home\n    cacheDir: ~/news-cache\n    defaults:\n      language: en\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		testName string
+		args     []string
+		vars     map[string]string
+		key      string
+		query    string
+	}{
+		{"Default profile", []string{"headlines"}, map[string]string{"HOME": home}, "from-work", "country=gb"},
+		{"Profile flag", []string{"sources", "-profile", "home"}, map[string]string{"HOME": home}, "from-home", "language=en"},
+		{"Profile environment", []string{"sources"}, map[string]string{"HOME": home, "NEWSAPI_PROFILE": "home"}, "from-home", "language=en"},
+		{"Flag overrides default", []string{"headlines", "-country", "us"}, map[string]string{"HOME": home}, "from-work", "country=us"},
+		{"Environment default", []string{"headlines"}, map[string]string{"HOME": home, "NEWSAPI_COUNTRY": "de"}, "from-work", "country=de"},
+		{"Key environment", []string{"headlines"}, map[string]string{"HOME": home, "NEWSAPI_KEY": "from-env"}, "from-env", "country=gb"},
+		{"Sources skip country", []string{"headlines", "-sources", "bbc-news"}, map[string]string{"HOME": home}, "from-work", "sources=bbc-news"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			runCmd(v.args, v.vars)
+			if k := last.Header.Get("X-Api-Key"); k != v.key || last.URL.RawQuery != v.query {
+				t.Fatalf("Expected key '%v' and query '%v' got '%v' and '%v'", v.key, v.query, k, last.URL.RawQuery)
+			}
+		})
+	}
+
+	_, stderr, code := runCmd([]string{"sources", "-profile", "other"}, map[string]string{"HOME": home})
+	if code != 1 || !strings.Contains(stderr, "Unknown profile other, expected one of home, work") {
+		t.Fatalf("Expected unknown profile error got %v '%v'", code, stderr)
+	}
+
+	if stdout, _, _ := runCmd([]string{completeCommand, "headlines", "-profile", ""}, map[string]string{"HOME": home}); stdout != "home\nwork\n" {
+		t.Fatalf("Expected profile names to be completed got '%v'", stdout)
+	}
+
+	e := &env{getenv: func(k string) string { return map[string]string{"HOME": home, "NEWSAPI_PROFILE": "home"}[k] }}
+	if d := cacheDir(e, &globalFlags{}); d != filepath.Join(home, "news-cache") {
+		t.Fatalf("Expected the profile's cache dir got %v", d)
+	}
+	e = &env{getenv: func(k string) string { return map[string]string{"HOME": home}[k] }}
+	if d := cacheDir(e, &globalFlags{profile: "home"}); d != filepath.Join(home, "news-cache") {
+		t.Fatalf("Expected the cache dir of the -profile flag's profile got %v", d)
+	}
+	if d := cacheDir(e, &globalFlags{}); d != filepath.Join(home, ".cache", "newsapi") {
+		t.Fatalf("Expected the default cache dir got %v", d)
+	}
+	runCmd([]string{completeCommand, "everything", "-profile", "home", "-sources", ""}, map[string]string{"HOME": home, "NEWSAPI_KEY": "abc"})
+	if _, err := os.Stat(filepath.Join(home, "news-cache", "sources.json")); err != nil {
+		t.Fatalf("Expected completion to cache sources in the -profile flag's cache dir: %v", err)
+	}
+
+	stdout, stderr, code := runCmdInput(context.Background(), []string{"repl"}, map[string]string{"HOME": home, "NEWSAPI_PLAN": "developer", "NEWSAPI_KEY": "abc"}, "bitcoin\n")
+	if code != 0 || !strings.Contains(stdout, "used 1 request, 99 left") {
+		t.Fatalf("Expected the profile's plan to be used got %v '%v' '%v'", code, stdout, stderr)
+	}
+}
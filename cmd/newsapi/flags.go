@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+//globalFlags are the flags shared by every command that calls NewsAPI
+type globalFlags struct {
+	key      string
+	config   string
+	profile  string
+	baseURL  string
+	format   string
+	fields   string
+	template string
+	timeout  time.Duration
+	dryRun   bool
+	out      *output         //Set by parse from format, fields and template
+	settings newsapi.Profile //Set by client from the config file and environment
+}
+
+func (g *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.key, "key", "", "NewsAPI key, overrides NEWSAPI_KEY and the config file")
+	fs.StringVar(&g.config, "config", "", "Config file (default $NEWSAPI_CONFIG or $XDG_CONFIG_HOME/newsapi/config.yaml)")
+	fs.StringVar(&g.profile, "profile", "", "Config file profile to use (default $NEWSAPI_PROFILE or the config file's profile)")
+	fs.StringVar(&g.baseURL, "base-url", "", "Base URL of a NewsAPI compatible server")
+	fs.StringVar(&g.format, "format", formatTable, "Output format: "+strings.Join(formats, ", "))
+	fs.StringVar(&g.fields, "fields", "", "Comma separated fields to show in table and CSV output, for example publishedAt,title,url")
+	fs.StringVar(&g.template, "template", "", "Go text/template run for each article or source, for example '{{date \"Jan 2\" .PublishedAt}} {{.Title}}'")
+	fs.DurationVar(&g.timeout, "timeout", 30*time.Second, "Timeout for each request")
+	fs.BoolVar(&g.dryRun, "dry-run", false, "Print the request that would be sent without sending it")
+}
+
+//client creates a Client using the key from the flags, the environment or the config file in that order
+//The base URL, plan and default parameters come from the flags, the environment or the config file profile
+func (g *globalFlags) client(e *env) (*newsapi.Client, error) {
+	settings, err := loadConfig(e, g.config, g.profile)
+	if err != nil {
+		return nil, err
+	}
+	g.settings = settings
+	key := g.key
+	if key == "" {
+		key = string(settings.Key)
+	}
+	if key == "" {
+		return nil, errors.New("No API key, use -key, NEWSAPI_KEY or the config file")
+	}
+	c := newsapi.New(key, newsapi.WithProfileConfig(settings))
+	c.HTTPClient.Timeout = g.timeout
+	if g.baseURL != "" {
+		newsapi.WithBaseURL(g.baseURL)(c)
+	}
+	if g.dryRun {
+		newsapi.WithDryRun()(c)
+	}
+	return c, nil
+}
+
+//paramFlags maps command flags to NewsAPI parameters, flags left unset aren't sent
+type paramFlags struct {
+	strs  map[string]*string
+	lists map[string]*string
+	ints  map[string]*int
+}
+
+func newParamFlags() *paramFlags {
+	return &paramFlags{
+		strs:  make(map[string]*string),
+		lists: make(map[string]*string),
+		ints:  make(map[string]*int),
+	}
+}
+
+//str adds a flag for a string parameter
+func (p *paramFlags) str(fs *flag.FlagSet, name, param, usage string) {
+	p.strs[param] = fs.String(name, "", usage)
+}
+
+//list adds a flag for a comma separated list parameter
+func (p *paramFlags) list(fs *flag.FlagSet, name, param, usage string) {
+	p.lists[param] = fs.String(name, "", usage)
+}
+
+//int adds a flag for an int parameter
+func (p *paramFlags) int(fs *flag.FlagSet, name, param, usage string) {
+	p.ints[param] = fs.Int(name, 0, usage)
+}
+
+//parameters returns the parameters for the flags that were set
+func (p *paramFlags) parameters() map[string]interface{} {
+	params := make(map[string]interface{})
+	for k, v := range p.strs {
+		if *v != "" {
+			params[k] = *v
+		}
+	}
+	for k, v := range p.lists {
+		if *v == "" {
+			continue
+		}
+		var l []string
+		for _, s := range strings.Split(*v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				l = append(l, s)
+			}
+		}
+		params[k] = l
+	}
+	for k, v := range p.ints {
+		if *v != 0 {
+			params[k] = *v
+		}
+	}
+	return params
+}
+
+//newFlagSet creates the flag set for a command with the global flags registered
+func newFlagSet(e *env, name string) (*flag.FlagSet, *globalFlags, *paramFlags) {
+	fs := flag.NewFlagSet("newsapi "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	g := &globalFlags{}
+	g.register(fs)
+	return fs, g, newParamFlags()
+}
+
+//parse parses args and checks the global flags, header lists the fields of the results the command writes
+func parse(fs *flag.FlagSet, g *globalFlags, args []string, header []string) error {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errReported
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Sprintf("Unexpected argument %v", fs.Arg(0)))
+	}
+	out, err := newOutput(g.format, g.fields, g.template)
+	if err != nil {
+		return err
+	}
+	g.out = out
+	if header == nil {
+		return nil
+	}
+	return out.check(header)
+}
@@ -0,0 +1,127 @@
+//Command newsapi queries NewsAPI from the command line
+//
+//	newsapi headlines -country gb -category technology
+//	newsapi everything -q golang -from 2024-01-01 -format csv
+//	newsapi sources -language en -format json
+//
+//The API key is read from the -key flag, then the NEWSAPI_KEY environment variable, then the config file
+//The config file, $XDG_CONFIG_HOME/newsapi/config.yaml by default, holds named profiles selected with -profile or NEWSAPI_PROFILE
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+//env holds the process state commands use so they can be run from tests
+type env struct {
+	ctx    context.Context //Cancelled when the user interrupts a command that handles interrupts, see interruptible
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+//command is a newsapi subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(e *env, args []string) error
+}
+
+//commands returns the subcommands in the order they're listed in the usage
+func commands() []command {
+	return []command{
+		{"headlines", "Show top headlines", runHeadlines},
+		{"everything", "Search every article", runEverything},
+		{"sources", "List news sources", runSources},
+		{"search", "List, run and add saved searches", runSearch},
+		{"watch", "Print new articles for a headlines or everything query as they appear", runWatch},
+		{"snapshot", "Save the top headlines to a file", runSnapshot},
+		{"diff", "Show what changed between two top headlines snapshots", runDiff},
+		{"repl", "Write everything queries interactively with syntax checking", runREPL},
+		{"tui", "Browse headlines, searches and saved searches interactively", runTUI},
+		{"mock", "Serve fake NewsAPI replies locally for development", runMock},
+		{"completion", "Print a bash, zsh or fish completion script", runCompletion},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{ctx: context.Background(), stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}))
+}
+
+//interruptible returns a copy of e whose ctx is also cancelled when the user interrupts the process
+//Only long running commands that stop cleanly on an interrupt use it, the rest exit with the default signal handling
+func interruptible(e *env) (*env, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(e.ctx, os.Interrupt)
+	ie := *e
+	ie.ctx = ctx
+	return &ie, stop
+}
+
+//run runs the subcommand named by args[0] and returns the exit code
+func run(args []string, e *env) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return 2
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage(e.stdout)
+		return 0
+	case completeCommand:
+		runComplete(e, args[1:])
+		return 0
+	}
+	for _, c := range commands() {
+		if c.name != args[0] {
+			continue
+		}
+		err := c.run(e, args[1:])
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		if err == errReported {
+			return 2
+		}
+		var ue usageError
+		if errors.As(err, &ue) {
+			fmt.Fprintf(e.stderr, "newsapi %v: %v\n", c.name, err)
+			return 2
+		}
+		if err != nil {
+			fmt.Fprintf(e.stderr, "newsapi %v: %v\n", c.name, err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(e.stderr, "newsapi: unknown command %v\n", args[0])
+	usage(e.stderr)
+	return 2
+}
+
+//errReported is returned when the flag package has already printed the problem and the usage
+var errReported = errors.New("Invalid flags")
+
+//usageError is returned for mistakes in how a command was called
+type usageError string
+
+func (u usageError) Error() string {
+	return string(u)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: newsapi <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-12v %v\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run newsapi <command> -h for the flags of a command")
+}
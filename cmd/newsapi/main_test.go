@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+//testServer serves the package fixtures and records the last request
+func testServer(t *testing.T) (*httptest.Server, *http.Request) {
+	fixtures := map[string]string{
+		"/top-headlines": "../../testdata/topheadlines_sucess.json",
+		"/everything":    "../../testdata/everything_sucess.json",
+		"/sources":       "../../testdata/sources_sucess.json",
+	}
+	last := &http.Request{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*last = *r
+		if r.Header.Get("X-Api-Key") != "abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error","code":"apiKeyInvalid","message":"Your API key is invalid or incorrect."}`))
+			return
+		}
+		d, err := ioutil.ReadFile(fixtures[r.URL.Path])
+		if err != nil {
+			t.Error(err)
+		}
+		w.Write(d)
+	}))
+	t.Cleanup(s.Close)
+	return s, last
+}
+
+//runCmd runs the CLI with args and the given environment variables
+func runCmd(args []string, vars map[string]string) (string, string, int) {
+	return runCmdContext(context.Background(), args, vars)
+}
+
+//runCmdContext works like runCmd with ctx standing in for the user interrupting the command
+func runCmdContext(ctx context.Context, args []string, vars map[string]string) (string, string, int) {
+	return runCmdInput(ctx, args, vars, "")
+}
+
+//runCmdInput works like runCmdContext with stdin standing in for what the user types
+func runCmdInput(ctx context.Context, args []string, vars map[string]string, stdin string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	e := &env{
+		ctx:    ctx,
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(k string) string { return vars[k] },
+	}
+	code := run(args, e)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestCommands(t *testing.T) {
+	s, last := testServer(t)
+
+	tt := []struct {
+		testName string
+		args     []string
+		query    string
+		contains string
+		lines    int
+	}{
+		{"Headlines table", []string{"headlines", "-country", "gb", "-category", "technology"}, "category=technology&country=gb", "PUBLISHED", 21},
+		{"Headlines sources", []string{"headlines", "-sources", "bbc-news, cnn", "-page-size", "5", "-page", "2"}, "page=2&pageSize=5&sources=bbc-news%2Ccnn", "SOURCE", 21},
+		{"Everything NDJSON", []string{"everything", "-q", "bitcoin", "-language", "en", "-sort-by", "popularity", "-format", "ndjson"}, "language=en&q=bitcoin&sortBy=popularity", `"title":"Bitcoin Bitcoin Bitcoin BITCOIN!! So Sue Us."`, 20},
+		{"Everything dates and domains", []string{"everything", "-q", "a", "-from", "2024-01-01", "-to", "2024-01-02", "-domains", "bbc.co.uk", "-format", "csv"}, "domains=bbc.co.uk&from=2024-01-01&q=a&to=2024-01-02", "sourceId,sourceName,author,title", 0},
+		{"Everything JSON", []string{"everything", "-q", "a", "-format", "json"}, "q=a", `"totalResults": 168748`, 0},
+		{"Sources table", []string{"sources", "-language", "en", "-country", "us"}, "country=us&language=en", "abc-news", 0},
+		{"Sources CSV", []string{"sources", "-category", "general", "-format", "csv"}, "category=general", "id,name,description,url,category,language,country", 0},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			args := append(v.args, "-key", "abc", "-base-url", s.URL)
+			stdout, stderr, code := runCmd(args, nil)
+			if code != 0 {
+				t.Fatalf("Expected exit code 0 got %v: %v", code, stderr)
+			}
+			if last.URL.RawQuery != v.query {
+				t.Fatalf("Expected query '%v' got '%v'", v.query, last.URL.RawQuery)
+			}
+			if !strings.Contains(stdout, v.contains) {
+				t.Fatalf("Expected output to contain '%v' got '%v'", v.contains, stdout)
+			}
+			if v.lines > 0 && strings.Count(stdout, "\n") != v.lines {
+				t.Fatalf("Expected %v lines got %v", v.lines, strings.Count(stdout, "\n"))
+			}
+		})
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	s, _ := testServer(t)
+
+	tt := []struct {
+		testName string
+		args     []string
+		code     int
+		stderr   string
+	}{
+		{"No command", nil, 2, "Usage: newsapi"},
+		{"Unknown command", []string{"nope"}, 2, "unknown command nope"},
+		{"Unknown flag", []string{"sources", "-nope"}, 2, "flag provided but not defined"},
+		{"Extra argument", []string{"sources", "-key", "abc", "extra"}, 2, "Unexpected argument extra"},
+		{"Bad format", []string{"sources", "-key", "abc", "-format", "xml"}, 2, "Unsupported format xml"},
+		{"Invalid parameter", []string{"headlines", "-key", "abc", "-country", "xx", "-base-url", s.URL}, 1, "Unsupported country code xx"},
+		{"API error", []string{"sources", "-key", "wrong", "-base-url", s.URL}, 1, "Your API key is invalid or incorrect."},
+		{"No key", []string{"sources"}, 1, "No API key"},
+		{"Missing config", []string{"sources", "-config", "/nonexistent/config.json"}, 1, "no such file"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			_, stderr, code := runCmd(v.args, map[string]string{"HOME": t.TempDir()})
+			if code != v.code {
+				t.Fatalf("Expected exit code %v got %v", v.code, code)
+			}
+			if !strings.Contains(stderr, v.stderr) {
+				t.Fatalf("Expected stderr to contain '%v' got '%v'", v.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestHelp(t *testing.T) {
+	stdout, _, code := runCmd([]string{"help"}, nil)
+	if code != 0 || !strings.Contains(stdout, "headlines") {
+		t.Fatalf("Unexpected help output %v '%v'", code, stdout)
+	}
+	_, stderr, code := runCmd([]string{"everything", "-h"}, nil)
+	if code != 0 || !strings.Contains(stderr, "-sort-by") {
+		t.Fatalf("Unexpected help output %v '%v'", code, stderr)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	stdout, stderr, code := runCmd([]string{"everything", "-q", "test", "-dry-run", "-base-url", "http://127.0.0.1:1"}, map[string]string{"NEWSAPI_KEY": "abc"})
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 got %v: %v", code, stderr)
+	}
+	if stdout != "GET http://127.0.0.1:1/everything?q=test\n" {
+		t.Fatalf("Unexpected dry run output '%v'", stdout)
+	}
+}
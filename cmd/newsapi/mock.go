@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//mockEndpoints are the endpoint names the mock server answers, taken from the last element of the request path
+var mockEndpoints = []string{"top-headlines", "everything", "sources"}
+
+//mockErrors maps the NewsAPI error codes the mock server can inject to their status codes
+var mockErrors = map[string]int{
+	"apiKeyDisabled":     http.StatusUnauthorized,
+	"apiKeyExhausted":    http.StatusTooManyRequests,
+	"apiKeyInvalid":      http.StatusUnauthorized,
+	"apiKeyMissing":      http.StatusUnauthorized,
+	"parameterInvalid":   http.StatusBadRequest,
+	"parametersMissing":  http.StatusBadRequest,
+	"rateLimited":        http.StatusTooManyRequests,
+	"sourcesTooMany":     http.StatusBadRequest,
+	"sourceDoesNotExist": http.StatusBadRequest,
+	"unexpectedError":    http.StatusInternalServerError,
+}
+
+//cassette is a recording of NewsAPI replies
+//
+//	{"interactions": [{"endpoint": "top-headlines", "query": "country=gb", "status": 200, "body": {"status": "ok", ...}}]}
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+//interaction is a request and the reply NewsAPI sent for it, the query never includes the API key
+type interaction struct {
+	Endpoint string          `json:"endpoint"`
+	Query    string          `json:"query"`
+	Status   int             `json:"status"`
+	Body     json.RawMessage `json:"body"`
+}
+
+func loadCassette(path string) (*cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("Invalid cassette %v: %v", path, err)
+	}
+	return &c, nil
+}
+
+//find returns the reply recorded for endpoint and query, or the first reply for endpoint when none match exactly
+func (c *cassette) find(endpoint, query string) (interaction, bool) {
+	var fallback *interaction
+	for i, in := range c.Interactions {
+		if in.Endpoint != endpoint {
+			continue
+		}
+		if in.Query == query {
+			return in, true
+		}
+		if fallback == nil {
+			fallback = &c.Interactions[i]
+		}
+	}
+	if fallback == nil {
+		return interaction{}, false
+	}
+	return *fallback, true
+}
+
+//mockServer answers NewsAPI requests from fixtures or a cassette
+type mockServer struct {
+	fixtures  string    //Directory holding <endpoint>.json for each endpoint
+	cassette  *cassette //Replies to play back
+	key       string    //When set requests must use this API key
+	latency   time.Duration
+	errorRate float64 //Fraction of requests answered with errorCode
+	errorCode string
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func (m *mockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if m.latency > 0 {
+		select {
+		case <-time.After(m.latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	endpoint := path.Base(r.URL.Path)
+	if index(mockEndpoints, endpoint) < 0 {
+		mockError(w, http.StatusNotFound, "routeNotFound", fmt.Sprintf("No NewsAPI endpoint at %v", r.URL.Path))
+		return
+	}
+	key := requestKey(r)
+	if m.key != "" && key == "" {
+		mockError(w, http.StatusUnauthorized, "apiKeyMissing", "Your API key is missing.")
+		return
+	}
+	if m.key != "" && key != m.key {
+		mockError(w, http.StatusUnauthorized, "apiKeyInvalid", "Your API key is invalid or incorrect.")
+		return
+	}
+	if m.inject() {
+		mockError(w, mockErrors[m.errorCode], m.errorCode, "Injected "+m.errorCode+" error from newsapi mock.")
+		return
+	}
+	q := r.URL.Query()
+	q.Del("apiKey")
+	query := q.Encode()
+	if m.cassette != nil {
+		in, ok := m.cassette.find(endpoint, query)
+		if !ok {
+			mockError(w, http.StatusNotFound, "routeNotFound", fmt.Sprintf("No %v reply in the cassette", endpoint))
+			return
+		}
+		writeMockReply(w, in.Status, in.Body)
+		return
+	}
+	b, err := ioutil.ReadFile(filepath.Join(m.fixtures, endpoint+".json"))
+	if err != nil {
+		mockError(w, http.StatusNotFound, "routeNotFound", fmt.Sprintf("No fixture for %v: %v", endpoint, err))
+		return
+	}
+	writeMockReply(w, http.StatusOK, pageFixture(b, q))
+}
+
+//inject reports whether this request should be answered with the injected error
+func (m *mockServer) inject() bool {
+	if m.errorRate <= 0 {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rand.Float64() < m.errorRate
+}
+
+//requestKey returns the API key from any of the places NewsAPI accepts it
+func requestKey(r *http.Request) string {
+	if k := r.Header.Get("X-Api-Key"); k != "" {
+		return k
+	}
+	if k := r.Header.Get("Authorization"); strings.HasPrefix(k, "Bearer ") {
+		return strings.TrimPrefix(k, "Bearer ")
+	}
+	return r.URL.Query().Get("apiKey")
+}
+
+//pageFixture returns the page of articles in fixture b selected by the page and pageSize parameters
+//Fixtures without articles, such as sources, are returned unchanged
+func pageFixture(b []byte, q url.Values) []byte {
+	var f map[string]json.RawMessage
+	var articles []json.RawMessage
+	if json.Unmarshal(b, &f) != nil || json.Unmarshal(f["articles"], &articles) != nil {
+		return b
+	}
+	size, n := 20, 1
+	if v, err := strconv.Atoi(q.Get("pageSize")); err == nil && v > 0 {
+		size = v
+	}
+	if v, err := strconv.Atoi(q.Get("page")); err == nil && v > 0 {
+		n = v
+	}
+	start, end := (n-1)*size, n*size
+	if start > len(articles) {
+		start = len(articles)
+	}
+	if end > len(articles) {
+		end = len(articles)
+	}
+	f["articles"], _ = json.Marshal(articles[start:end])
+	if _, ok := f["totalResults"]; !ok {
+		f["totalResults"], _ = json.Marshal(len(articles))
+	}
+	p, err := json.Marshal(f)
+	if err != nil {
+		return b
+	}
+	return p
+}
+
+func writeMockReply(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func mockError(w http.ResponseWriter, status int, code, message string) {
+	b, _ := json.Marshal(map[string]string{"status": "error", "code": code, "message": message})
+	if status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "1")
+	}
+	writeMockReply(w, status, b)
+}
+
+func runMock(e *env, args []string) error {
+	fs := flag.NewFlagSet("newsapi mock", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	m := &mockServer{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	addr := fs.String("addr", "localhost:8080", "Address to listen on")
+	fs.StringVar(&m.fixtures, "fixtures", "", "Directory of replies named top-headlines.json, everything.json and sources.json")
+	cassettePath := fs.String("cassette", "", "Cassette of recorded replies to play back")
+	fs.StringVar(&m.key, "key", "", "Only accept requests using this API key")
+	fs.DurationVar(&m.latency, "latency", 0, "Delay before each reply")
+	fs.Float64Var(&m.errorRate, "error-rate", 0, "Fraction of requests to answer with -error, from 0 to 1")
+	fs.StringVar(&m.errorCode, "error", "unexpectedError", "NewsAPI error code to inject, for example rateLimited or apiKeyInvalid")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errReported
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Sprintf("Unexpected argument %v", fs.Arg(0)))
+	}
+	if (m.fixtures == "") == (*cassettePath == "") {
+		return usageError("Expected one of -fixtures or -cassette")
+	}
+	if m.errorRate < 0 || m.errorRate > 1 {
+		return usageError("Error rate must be between 0 and 1")
+	}
+	if _, ok := mockErrors[m.errorCode]; !ok {
+		return usageError(fmt.Sprintf("Unknown error code %v", m.errorCode))
+	}
+	if *cassettePath != "" {
+		c, err := loadCassette(*cassettePath)
+		if err != nil {
+			return err
+		}
+		m.cassette = c
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	e, stop := interruptible(e)
+	defer stop()
+	srv := &http.Server{Handler: m}
+	fmt.Fprintf(e.stdout, "Serving NewsAPI mock on http://%v, use -base-url http://%v\n", l.Addr(), l.Addr())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(l)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-e.ctx.Done():
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+//mockFixtures copies the package fixtures into a directory named the way newsapi mock expects
+func mockFixtures(t *testing.T) string {
+	dir := t.TempDir()
+	for name, f := range map[string]string{"top-headlines.json": "topheadlines_sucess.json", "sources.json": "sources_sucess.json"} {
+		d, err := ioutil.ReadFile(filepath.Join("../../testdata", f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.WriteFile(filepath.Join(dir, name), d, 0644)
+	}
+	return dir
+}
+
+func TestMockServer(t *testing.T) {
+	fixtures := mockFixtures(t)
+	cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+	ioutil.WriteFile(cassettePath, []byte(`{"interactions": [
+		{"endpoint": "everything", "query": "q=go", "status": 200, "body": {"status": "ok", "totalResults": 1, "articles": [{"title": "Go 2", "url": "https://go.dev"}]}},
+		{"endpoint": "everything", "query": "q=rust", "status": 200, "body": {"status": "ok", "totalResults": 1, "articles": [{"title": "Rust 2", "url": "https://rust-lang.org"}]}},
+		{"endpoint": "sources", "query": "", "status": 401, "body": {"status": "error", "code": "apiKeyDisabled", "message": "Your API key has been disabled."}}
+	]}`), 0644)
+	c, err := loadCassette(cassettePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		testName string
+		server   *mockServer
+		args     []string
+		code     int
+		stdout   string
+		stderr   string
+	}{
+		{"Fixture", &mockServer{fixtures: fixtures}, []string{"headlines", "-country", "gb", "-template", "{{.Title}}"}, 0, "11 Meditations To Help You Destress", ""},
+		{"Fixture paging", &mockServer{fixtures: fixtures}, []string{"headlines", "-country", "gb", "-page-size", "2", "-page", "2", "-template", "{{.Source.Name}}"}, 0, "NBC News\nCBS News\n", ""},
+		{"Fixture past the last page", &mockServer{fixtures: fixtures}, []string{"headlines", "-country", "gb", "-page-size", "20", "-page", "2", "-format", "csv", "-fields", "title"}, 0, "title\n", ""},
+		{"Fixture without articles", &mockServer{fixtures: fixtures}, []string{"sources", "-template", "{{.ID}}"}, 0, "abc-news\n", ""},
+		{"Missing fixture", &mockServer{fixtures: fixtures}, []string{"everything", "-q", "go"}, 1, "", "No fixture for everything"},
+		{"Key", &mockServer{fixtures: fixtures, key: "abc"}, []string{"sources"}, 0, "abc-news", ""},
+		{"Wrong key", &mockServer{fixtures: fixtures, key: "other"}, []string{"sources"}, 1, "", "Your API key is invalid or incorrect."},
+		{"Injected error", &mockServer{fixtures: fixtures, errorRate: 1, errorCode: "rateLimited", rand: rand.New(rand.NewSource(1))}, []string{"sources"}, 1, "", "Injected rateLimited error"},
+		{"Latency", &mockServer{fixtures: fixtures, latency: time.Second}, []string{"sources", "-timeout", "100ms"}, 1, "", "Client.Timeout exceeded"},
+		{"Cassette", &mockServer{cassette: c}, []string{"everything", "-q", "rust", "-template", "{{.Title}}"}, 0, "Rust 2\n", ""},
+		{"Cassette fallback", &mockServer{cassette: c}, []string{"everything", "-q", "java", "-template", "{{.Title}}"}, 0, "Go 2\n", ""},
+		{"Cassette error", &mockServer{cassette: c}, []string{"sources"}, 1, "", "Your API key has been disabled."},
+		{"Cassette missing endpoint", &mockServer{cassette: c}, []string{"headlines", "-country", "gb"}, 1, "", "No top-headlines reply in the cassette"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			s := httptest.NewServer(v.server)
+			defer s.Close()
+			stdout, stderr, code := runCmd(append(v.args, "-key", "abc", "-base-url", s.URL+"/v2"), nil)
+			if code != v.code || !strings.Contains(stdout, v.stdout) || !strings.Contains(stderr, v.stderr) {
+				t.Fatalf("Expected %v '%v' '%v' got %v '%v' '%v'", v.code, v.stdout, v.stderr, code, stdout, stderr)
+			}
+		})
+	}
+
+	res, err := http.Get(httptest.NewServer(&mockServer{fixtures: fixtures}).URL + "/v2/nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected 404 for an unknown endpoint got %v", res.StatusCode)
+	}
+}
+
+func TestRunMock(t *testing.T) {
+	fixtures := mockFixtures(t)
+	tt := []struct {
+		testName string
+		args     []string
+		stderr   string
+	}{
+		{"No replies", nil, "Expected one of -fixtures or -cassette"},
+		{"Two sources", []string{"-fixtures", fixtures, "-cassette", "c.json"}, "Expected one of -fixtures or -cassette"},
+		{"Error rate", []string{"-fixtures", fixtures, "-error-rate", "2"}, "Error rate must be between 0 and 1"},
+		{"Error code", []string{"-fixtures", fixtures, "-error", "nope"}, "Unknown error code nope"},
+		{"Missing cassette", []string{"-cassette", filepath.Join(fixtures, "missing.json")}, "no such file"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			_, stderr, code := runCmd(append([]string{"mock"}, v.args...), nil)
+			if code == 0 || !strings.Contains(stderr, v.stderr) {
+				t.Fatalf("Expected '%v' got %v '%v'", v.stderr, code, stderr)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, w := io.Pipe()
+	done := make(chan int)
+	go func() {
+		done <- run([]string{"mock", "-addr", "127.0.0.1:0", "-fixtures", fixtures}, &env{ctx: ctx, stdin: strings.NewReader(""), stdout: w, stderr: ioutil.Discard, getenv: func(string) string { return "" }})
+	}()
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := strings.Fields(line)
+	stdout, stderr, code := runCmd([]string{"sources", "-key", "abc", "-base-url", f[len(f)-1], "-template", "{{.ID}}"}, nil)
+	if code != 0 || !strings.HasPrefix(stdout, "abc-news\n") {
+		t.Fatalf("Expected sources from the mock server got %v '%v' '%v'", code, stdout, stderr)
+	}
+	cancel()
+	if code := <-done; code != 0 {
+		t.Fatalf("Expected exit code 0 after interrupt got %v", code)
+	}
+}
+
+func TestRequestKey(t *testing.T) {
+	tt := []struct {
+		testName string
+		header   map[string]string
+		query    string
+		expected string
+	}{
+		{"Header", map[string]string{"X-Api-Key": "abc"}, "", "abc"},
+		{"Bearer", map[string]string{"Authorization": "Bearer abc"}, "", "abc"},
+		{"Query", nil, "apiKey=abc", "abc"},
+		{"Other authorization", map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, "", ""},
+		{"Other authorization with query", map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, "apiKey=abc", "abc"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v2/sources?"+v.query, nil)
+			for k, h := range v.header {
+				r.Header.Set(k, h)
+			}
+			if k := requestKey(r); k != v.expected {
+				t.Fatalf("Expected key '%v' got '%v'", v.expected, k)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+const (
+	formatTable  = "table"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+)
+
+//formats lists the supported output formats
+var formats = []string{formatTable, formatJSON, formatNDJSON, formatCSV}
+
+//maxTitle is the most characters of a title shown in a table
+const maxTitle = 80
+
+func checkFormat(f string) error {
+	for _, v := range formats {
+		if f == v {
+			return nil
+		}
+	}
+	return usageError(fmt.Sprintf("Unsupported format %v, expected one of %v", f, strings.Join(formats, ", ")))
+}
+
+//articleHeader names the columns written by articleRow, all of them are written in CSV output by default
+var articleHeader = []string{"sourceId", "sourceName", "author", "title", "description", "url", "urlToImage", "publishedAt", "content"}
+
+//articleTable are the columns shown in table output by default
+var articleTable = []string{"publishedAt", "sourceName", "title", "url"}
+
+func articleRow(a newsapi.Article) []string {
+	return []string{a.Source.ID, a.Source.Name, a.Author, a.Title, a.Description, a.URL, a.URLToImage, a.PublishedAt, a.Content}
+}
+
+//sourceHeader names the columns written by sourceRow, all of them are written in CSV output by default
+var sourceHeader = []string{"id", "name", "description", "url", "category", "language", "country"}
+
+//sourceTable are the columns shown in table output by default
+var sourceTable = []string{"id", "name", "category", "language", "country"}
+
+func sourceRow(s newsapi.Source) []string {
+	return []string{s.ID, s.Name, s.Description, s.URL, s.Category, s.Language, s.Country}
+}
+
+//tableTitles overrides the column title used in tables for some fields, other fields are shown in upper case
+var tableTitles = map[string]string{"publishedAt": "PUBLISHED", "sourceName": "SOURCE"}
+
+//output controls how results are written
+type output struct {
+	format string
+	fields []string           //Columns for table and CSV output, nil for the defaults
+	tmpl   *template.Template //Executed for each article or source instead of using format
+}
+
+//newOutput checks format, fields and tmpl and creates the output they describe
+func newOutput(format, fields, tmpl string) (*output, error) {
+	if err := checkFormat(format); err != nil {
+		return nil, err
+	}
+	o := &output{format: format}
+	if fields != "" {
+		for _, f := range strings.Split(fields, ",") {
+			f = strings.TrimSpace(f)
+			if index(articleHeader, f) < 0 && index(sourceHeader, f) < 0 {
+				return nil, usageError(fmt.Sprintf("Unknown field %v, expected one of %v", f, strings.Join(append(append([]string{}, articleHeader...), sourceHeader...), ", ")))
+			}
+			o.fields = append(o.fields, f)
+		}
+	}
+	if tmpl != "" {
+		t, err := template.New("output").Funcs(templateFuncs).Parse(tmpl)
+		if err != nil {
+			return nil, usageError(fmt.Sprintf("Invalid template: %v", err))
+		}
+		o.tmpl = t
+	}
+	return o, nil
+}
+
+//columns returns the indexes in header of the selected fields, or of defaults when no fields were selected
+func (o *output) columns(header, defaults []string) ([]int, error) {
+	fields := o.fields
+	if fields == nil {
+		fields = defaults
+	}
+	cols := make([]int, len(fields))
+	for i, f := range fields {
+		if cols[i] = index(header, f); cols[i] < 0 {
+			return nil, usageError(fmt.Sprintf("Field %v isn't available here, expected one of %v", f, strings.Join(header, ", ")))
+		}
+	}
+	return cols, nil
+}
+
+//check returns an error when a selected field isn't in header, so mistakes are reported before any request is sent
+func (o *output) check(header []string) error {
+	_, err := o.columns(header, nil)
+	return err
+}
+
+//articles writes r to w
+func (o *output) articles(w io.Writer, r newsapi.ArticleResults) error {
+	if o.format == formatJSON && o.tmpl == nil {
+		return writeJSON(w, r)
+	}
+	return (&articleStream{w: w, out: o}).write(r.Articles)
+}
+
+//sources writes r to w
+func (o *output) sources(w io.Writer, r newsapi.SourceResults) error {
+	switch {
+	case o.tmpl != nil:
+		for _, s := range r.Source {
+			if err := o.execute(w, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	case o.format == formatJSON:
+		return writeJSON(w, r)
+	case o.format == formatNDJSON:
+		for _, s := range r.Source {
+			if err := writeNDJSON(w, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	defaults := sourceTable
+	if o.format == formatCSV {
+		defaults = sourceHeader
+	}
+	cols, err := o.columns(sourceHeader, defaults)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(r.Source))
+	for i, s := range r.Source {
+		rows[i] = pick(sourceRow(s), cols)
+	}
+	if o.format == formatCSV {
+		return writeCSV(w, pick(sourceHeader, cols), rows)
+	}
+	return writeTable(w, titles(pick(sourceHeader, cols)), rows)
+}
+
+//execute runs the template for v and ends the output with a newline
+func (o *output) execute(w io.Writer, v interface{}) error {
+	var b strings.Builder
+	if err := o.tmpl.Execute(&b, v); err != nil {
+		return err
+	}
+	s := b.String()
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+//articleStream writes articles a batch at a time, any header is only written before the first batch
+type articleStream struct {
+	w       io.Writer
+	out     *output
+	started bool
+}
+
+func (s *articleStream) write(articles []newsapi.Article) error {
+	first := !s.started
+	s.started = true
+	o := s.out
+	switch {
+	case o.tmpl != nil:
+		for _, a := range articles {
+			if err := o.execute(s.w, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	case o.format == formatJSON, o.format == formatNDJSON:
+		for _, a := range articles {
+			if err := writeNDJSON(s.w, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	defaults := articleTable
+	if o.format == formatCSV {
+		defaults = articleHeader
+	}
+	cols, err := o.columns(articleHeader, defaults)
+	if err != nil {
+		return err
+	}
+	header := pick(articleHeader, cols)
+	rows := make([][]string, len(articles))
+	for i, a := range articles {
+		rows[i] = pick(articleRow(a), cols)
+	}
+	if o.format == formatCSV {
+		cw := csv.NewWriter(s.w)
+		if first {
+			cw.Write(header)
+		}
+		cw.WriteAll(rows)
+		return cw.Error()
+	}
+	if t := index(header, "title"); t >= 0 {
+		for _, r := range rows {
+			r[t] = truncate(r[t], maxTitle)
+		}
+	}
+	if !first {
+		header = nil
+	}
+	return writeTable(s.w, titles(header), rows)
+}
+
+//index returns the position of s in l or -1
+func index(l []string, s string) int {
+	for i, v := range l {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+//pick returns the values of row at cols
+func pick(row []string, cols []int) []string {
+	r := make([]string, len(cols))
+	for i, c := range cols {
+		r[i] = row[c]
+	}
+	return r
+}
+
+//titles returns the table column titles for fields
+func titles(fields []string) []string {
+	if fields == nil {
+		return nil
+	}
+	t := make([]string, len(fields))
+	for i, f := range fields {
+		if t[i] = tableTitles[f]; t[i] == "" {
+			t[i] = strings.ToUpper(f)
+		}
+	}
+	return t
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeNDJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.WriteAll(rows)
+	return cw.Error()
+}
+
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if header != nil {
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+	}
+	for _, r := range rows {
+		cells := make([]string, len(r))
+		for i, c := range r {
+			cells[i] = cell(c)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+//cell flattens whitespace in s so it fits in a single table cell
+func cell(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+//truncate shortens s to at most n characters, with no room for an ellipsis when n is 3 or less
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:max(n, 0)])
+	}
+	return string(r[:n-3]) + "..."
+}
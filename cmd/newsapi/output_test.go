@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+func TestWriteArticles(t *testing.T) {
+	r := newsapi.ArticleResults{
+		Status:       "ok",
+		TotalResults: 1,
+		Articles: []newsapi.Article{{
+			Source:      newsapi.Source{ID: "bbc-news", Name: "BBC News"},
+			Title:       "Line one\nline\ttwo, \"quoted\"",
+			URL:         "https://bbc.co.uk/a",
+			PublishedAt: "2024-01-01T00:00:00Z",
+		}},
+	}
+
+	tt := []struct {
+		testName string
+		format   string
+		expected string
+	}{
+		{"Table", formatTable, "PUBLISHED             SOURCE    TITLE                        URL\n2024-01-01T00:00:00Z  BBC News  Line one line two, \"quoted\"  https://bbc.co.uk/a\n"},
+		{"CSV", formatCSV, "sourceId,sourceName,author,title,description,url,urlToImage,publishedAt,content\nbbc-news,BBC News,,\"Line one\nline\ttwo, \"\"quoted\"\"\",,https://bbc.co.uk/a,,2024-01-01T00:00:00Z,\n"},
+		{"NDJSON", formatNDJSON, `{"source":{"id":"bbc-news","name":"BBC News"},"author":"","title":"Line one\nline\ttwo, \"quoted\"","description":"","url":"https://bbc.co.uk/a","urlToImage":"","publishedAt":"2024-01-01T00:00:00Z","content":""}` + "\n"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (&output{format: v.format}).articles(&buf, r); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != v.expected {
+				t.Fatalf("Expected '%v' got '%v'", v.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tt := []struct {
+		testName string
+		s        string
+		n        int
+		expected string
+	}{
+		{"Short", "abc", 5, "abc"},
+		{"Exact", "abcde", 5, "abcde"},
+		{"Long", "abcdefgh", 5, "ab..."},
+		{"Multibyte", "ééééééé", 5, "éé..."},
+		{"No room for ellipsis", "abcdefgh", 2, "ab"},
+		{"Only ellipsis length", "abcdefgh", 3, "abc"},
+		{"Zero", "abc", 0, ""},
+		{"Negative", "abc", -1, ""},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			if r := truncate(v.s, v.n); r != v.expected {
+				t.Fatalf("Expected '%v' got '%v'", v.expected, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+//replResults is how many articles the repl shows for each query
+const replResults = 5
+
+//replCache is how long the repl reuses replies, so running the same query again doesn't use quota
+const replCache = 10 * time.Minute
+
+const replHelp = `Type an everything query to check it, see its URL and cost, and run it:
+  crypto AND (ethereum OR litecoin) NOT bitcoin
+Commands:
+  :set <name> <value>           Set a parameter: sources, domains, from, to, language, sort-by or page-size
+  :unset <name>                 Remove a parameter
+  :params                       Show the parameters
+  :save <name> [description]    Save the last valid query and parameters as a saved search
+  :replace <name> [description] Like :save but replaces any saved search with the same name
+  :help                         Show this help
+  :quit                         Quit`
+
+//replParams maps the names :set accepts to everything parameters
+var replParams = map[string]string{
+	"sources":   "sources",
+	"domains":   "domains",
+	"from":      "from",
+	"to":        "to",
+	"language":  "language",
+	"sort-by":   "sortBy",
+	"sortBy":    "sortBy",
+	"page-size": "pageSize",
+	"pageSize":  "pageSize",
+}
+
+//repl is the state of an interactive query session
+type repl struct {
+	e        *env
+	c        *newsapi.Client
+	preview  *newsapi.Client //Dry run Client used to show the URL of a query without sending it
+	plan     *newsapi.Plan
+	check    bool //Only check queries, never send them
+	searches string
+	params   map[string]interface{}
+	last     string //Last query that passed validation
+}
+
+func runREPL(e *env, args []string) error {
+	e, stop := interruptible(e)
+	defer stop()
+	fs, g, _ := newFlagSet(e, "repl")
+	file := searchesFlag(e, fs)
+	plan := fs.String("plan", "", "NewsAPI plan to estimate costs and enforce limits for: developer or business (default the config file profile's plan)")
+	check := fs.Bool("check", false, "Only check queries and show their URL, never send them")
+	if err := parse(fs, g, args, nil); err != nil {
+		return err
+	}
+	r := &repl{e: e, check: *check || g.dryRun, searches: *file, params: make(map[string]interface{})}
+	if *plan != "" {
+		p, ok := newsapi.PlanByName(*plan)
+		if !ok {
+			return usageError(fmt.Sprintf("Unknown plan %v, expected developer or business", *plan))
+		}
+		r.plan = &p
+	}
+	c, err := g.client(e)
+	if err != nil {
+		return err
+	}
+	if p, ok := newsapi.PlanByName(g.settings.Plan); ok && r.plan == nil {
+		r.plan = &p
+	}
+	if r.plan != nil {
+		newsapi.WithPlan(*r.plan)(c)
+	}
+	newsapi.WithCache(replCache)(c)
+	r.c = c
+	if r.preview, err = g.client(e); err != nil {
+		return err
+	}
+	newsapi.WithDryRun()(r.preview)
+	fmt.Fprintln(e.stdout, replHelp)
+	return r.loop()
+}
+
+//loop reads queries and commands from stdin until :quit, the end of the input or an interrupt
+func (r *repl) loop() error {
+	in := newLineReader(r.e.stdin)
+	for {
+		fmt.Fprint(r.e.stdout, "q> ")
+		line, ok := in.next(r.e.ctx)
+		if !ok {
+			fmt.Fprintln(r.e.stdout)
+			if r.e.ctx.Err() != nil {
+				return nil
+			}
+			return in.err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case line == ":quit" || line == ":q":
+			return nil
+		case strings.HasPrefix(line, ":"):
+			r.command(line[1:])
+		default:
+			r.query(line)
+		}
+	}
+}
+
+//command runs a : command
+func (r *repl) command(line string) {
+	f := strings.Fields(line)
+	if len(f) == 0 {
+		r.message("Expected a command after :, see :help")
+		return
+	}
+	name, args := f[0], f[1:]
+	switch name {
+	case "help", "h", "?":
+		fmt.Fprintln(r.e.stdout, replHelp)
+	case "set":
+		if len(args) < 2 {
+			r.message("Expected a name and a value, for example :set language en")
+			return
+		}
+		r.set(args[0], strings.Join(args[1:], " "))
+	case "unset":
+		if len(args) != 1 {
+			r.message("Expected a name, for example :unset language")
+			return
+		}
+		if p, ok := replParams[args[0]]; ok {
+			delete(r.params, p)
+		}
+		r.showParams()
+	case "params":
+		r.showParams()
+	case "save", "replace":
+		if len(args) == 0 {
+			r.message("Expected a name, for example :save uk-crypto")
+			return
+		}
+		r.save(args[0], strings.Join(args[1:], " "), name == "replace")
+	default:
+		r.message(fmt.Sprintf("Unknown command :%v, enter :help for help", name))
+	}
+}
+
+//set sets the parameter name to value when the result is valid
+func (r *repl) set(name, value string) {
+	p, ok := replParams[name]
+	if !ok {
+		r.message(fmt.Sprintf("Unknown parameter %v, expected sources, domains, from, to, language, sort-by or page-size", name))
+		return
+	}
+	var v interface{} = value
+	switch p {
+	case "pageSize":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			r.message(fmt.Sprintf("Expected a number for %v got %v", name, value))
+			return
+		}
+		v = n
+	case "sources", "domains":
+		var l []string
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				l = append(l, s)
+			}
+		}
+		v = l
+	}
+	old, had := r.params[p]
+	r.params[p] = v
+	if _, err := r.request(""); err != nil {
+		if had {
+			r.params[p] = old
+		} else {
+			delete(r.params, p)
+		}
+		r.message(err.Error())
+		return
+	}
+	r.showParams()
+}
+
+//request returns the everything request for q and the parameters, an empty q is left out
+func (r *repl) request(q string) (newsapi.Request, error) {
+	return r.search("repl", "", q).Request()
+}
+
+//search returns the saved search for q and the parameters
+func (r *repl) search(name, description, q string) newsapi.SavedSearch {
+	p := make(map[string]interface{}, len(r.params)+1)
+	for k, v := range r.params {
+		p[k] = v
+	}
+	if q != "" {
+		p["q"] = q
+	}
+	return newsapi.SavedSearch{Name: name, Description: description, Endpoint: newsapi.EndpointEverything, Parameters: p}
+}
+
+//query checks q, shows its URL and cost, then runs it unless only checking
+func (r *repl) query(q string) {
+	if err := newsapi.ValidateQuery(q); err != nil {
+		var qe *newsapi.QueryError
+		if errors.As(err, &qe) {
+			fmt.Fprintf(r.e.stdout, "  %v\n  %v^\n", q, strings.Repeat(" ", qe.Offset))
+		}
+		r.message(err.Error())
+		return
+	}
+	req, err := r.request(q)
+	if err != nil {
+		r.message(err.Error())
+		return
+	}
+	r.last = q
+	var dr *newsapi.DryRunError
+	if _, err := r.preview.GetEverything(req.Parameters); !errors.As(err, &dr) {
+		if err != nil {
+			r.message(err.Error())
+		}
+		return
+	}
+	fmt.Fprintln(r.e.stdout, dr.Method, dr.URL)
+	if r.check {
+		r.message("Valid, running it costs 1 request")
+		return
+	}
+	res, err := r.c.GetEverythingContext(r.e.ctx, req.Parameters)
+	if err != nil {
+		r.message(err.Error())
+		return
+	}
+	r.results(res, req.Parameters)
+}
+
+//results shows the result count, what the request cost and the first few articles
+func (r *repl) results(res newsapi.ArticleResults, p map[string]interface{}) {
+	cost := "used 1 request"
+	if res.Response != nil && res.Response.FromCache {
+		cost = "cached, no request used"
+	}
+	if n, ok := r.c.QuotaRemaining(); ok {
+		cost += fmt.Sprintf(", %v left", n)
+	}
+	fmt.Fprintf(r.e.stdout, "%v results (%v)\n", res.TotalResults, cost)
+	pageSize := 20 //NewsAPI default
+	if n, ok := p["pageSize"].(int); ok && n > 0 {
+		pageSize = n
+	}
+	reachable := int(res.TotalResults)
+	if r.plan != nil && r.plan.MaxResults > 0 && reachable > r.plan.MaxResults {
+		reachable = r.plan.MaxResults
+	}
+	if pages := (reachable + pageSize - 1) / pageSize; pages > 1 {
+		fmt.Fprintf(r.e.stdout, "Reading all %v reachable results costs %v requests at page size %v\n", reachable, pages, pageSize)
+	}
+	n := len(res.Articles)
+	if n > replResults {
+		n = replResults
+	}
+	rows := make([][]string, n)
+	for i, a := range res.Articles[:n] {
+		rows[i] = []string{"", ago(a.PublishedAt), a.Source.Name, truncate(cell(a.Title), maxTitle)}
+	}
+	writeTable(r.e.stdout, nil, rows)
+}
+
+//showParams lists the parameters sent with every query
+func (r *repl) showParams() {
+	if len(r.params) == 0 {
+		r.message("No parameters set")
+		return
+	}
+	keys := make([]string, 0, len(r.params))
+	for k := range r.params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := r.params[k]
+		if l, ok := v.([]string); ok {
+			v = strings.Join(l, ",")
+		}
+		fmt.Fprintf(r.e.stdout, "%v=%v\n", k, v)
+	}
+}
+
+//save adds the last valid query and the parameters to the saved search file
+func (r *repl) save(name, description string, replace bool) {
+	if r.last == "" {
+		r.message("Nothing to save yet, enter a query first")
+		return
+	}
+	s, err := loadSearches(r.searches)
+	if err != nil {
+		r.message(err.Error())
+		return
+	}
+	if _, ok := s.Get(name); ok && !replace {
+		r.message(fmt.Sprintf("Saved search %v already exists, use :replace %v to overwrite it", name, name))
+		return
+	}
+	err = s.Add(r.search(name, description, r.last), replace)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(r.searches), 0700)
+	}
+	if err == nil {
+		err = s.Save(r.searches)
+	}
+	if err != nil {
+		r.message(err.Error())
+		return
+	}
+	r.message(fmt.Sprintf("Saved %q as %v, run it with newsapi search run %v", r.last, name, name))
+}
+
+func (r *repl) message(s string) {
+	fmt.Fprintln(r.e.stdout, s)
+}
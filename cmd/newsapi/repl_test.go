@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+func TestREPL(t *testing.T) {
+	s, last := testServer(t)
+	home := t.TempDir()
+	vars := map[string]string{"HOME": home}
+
+	tt := []struct {
+		testName string
+		args     []string
+		input    string
+		contains []string
+		query    string
+	}{
+		{"Query", nil, "bitcoin AND price\n", []string{"GET " + s.URL + "/everything?q=bitcoin+AND+price", "168748 results (used 1 request)", "Youbrandinc.com  Bitcoin Bitcoin"}, "q=bitcoin+AND+price"},
+		{"Cached", nil, "bitcoin\nbitcoin\n", []string{"168748 results (cached, no request used)"}, "q=bitcoin"},
+		{"Syntax error", nil, "bitcoin AND\n", []string{"  bitcoin AND\n          ^\n", "Invalid query at character 9: Expected a word, phrase or ( after AND"}, ""},
+		{"Parameters", nil, ":set language en\n:set sort-by popularity\n:set page-size 10\n:set domains bbc.co.uk, cnn.com\ngo\n", []string{"domains=bbc.co.uk,cnn.com\nlanguage=en\npageSize=10\nsortBy=popularity\n", "Reading all 168748 reachable results costs 16875 requests at page size 10"}, "domains=bbc.co.uk%2Ccnn.com&language=en&pageSize=10&q=go&sortBy=popularity"},
+		{"Invalid parameter", nil, ":set language xx\n:set page-size many\n:set nope 1\n:params\n", []string{"Unsupported language xx", "Expected a number for page-size got many", "Unknown parameter nope", "No parameters set"}, ""},
+		{"Page size out of range", nil, ":set page-size 0\n:set page-size -1\nbitcoin\n", []string{"Expected pageSize of at least 1 got 0", "Expected pageSize of at least 1 got -1", "168748 results", "costs 8438 requests at page size 20"}, "q=bitcoin"},
+		{"Unset", nil, ":set language en\n:unset language\n:params\n", []string{"No parameters set"}, ""},
+		{"Plan", []string{"-plan", "developer"}, "bitcoin\n", []string{"used 1 request, 99 left", "Reading all 100 reachable results costs 5 requests at page size 20"}, "q=bitcoin"},
+		{"Check", []string{"-check"}, "bitcoin\n", []string{"GET " + s.URL + "/everything?q=bitcoin", "Valid, running it costs 1 request"}, ""},
+		{"Unknown command", nil, ":nope\n", []string{"Unknown command :nope"}, ""},
+		{"Empty command", nil, ":\n", []string{"Expected a command after :, see :help"}, ""},
+		{"Save nothing", nil, ":save a\n", []string{"Nothing to save yet"}, ""},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			last.URL = nil
+			args := append([]string{"repl", "-key", "abc", "-base-url", s.URL}, v.args...)
+			stdout, stderr, code := runCmdInput(context.Background(), args, vars, v.input)
+			if code != 0 {
+				t.Fatalf("Expected exit code 0 got %v: %v", code, stderr)
+			}
+			for _, c := range v.contains {
+				if !strings.Contains(stdout, c) {
+					t.Fatalf("Expected output to contain '%v' got '%v'", c, stdout)
+				}
+			}
+			if v.query == "" {
+				if last.URL != nil {
+					t.Fatalf("Expected no request got '%v'", last.URL)
+				}
+			} else if last.URL == nil || last.URL.RawQuery != v.query {
+				t.Fatalf("Expected query '%v' got '%v'", v.query, last.URL)
+			}
+		})
+	}
+}
+
+func TestREPLSave(t *testing.T) {
+	s, _ := testServer(t)
+	home := t.TempDir()
+	vars := map[string]string{"HOME": home}
+	args := []string{"repl", "-key", "abc", "-base-url", s.URL}
+
+	stdout, _, _ := runCmdInput(context.Background(), args, vars, ":set language en\ncrypto -bitcoin\n:save crypto No bitcoin\nbad AND\n:save crypto\n:replace crypto Replaced\n")
+	for _, c := range []string{`Saved "crypto -bitcoin" as crypto`, "Saved search crypto already exists, use :replace crypto"} {
+		if !strings.Contains(stdout, c) {
+			t.Fatalf("Expected output to contain '%v' got '%v'", c, stdout)
+		}
+	}
+	ss, err := loadSearches(home + "/.config/newsapi/searches.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := ss.Get("crypto")
+	if !ok || got.Description != "Replaced" || got.Endpoint != newsapi.EndpointEverything || got.Parameters["q"] != "crypto -bitcoin" || got.Parameters["language"] != "en" {
+		t.Fatalf("Unexpected saved search %+v", got)
+	}
+}
+
+func TestREPLInterrupt(t *testing.T) {
+	stdin, w := io.Pipe()
+	defer w.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var stdout bytes.Buffer
+	start := time.Now()
+	code := run([]string{"repl", "-key", "abc"}, &env{ctx: ctx, stdin: stdin, stdout: &stdout, stderr: ioutil.Discard, getenv: func(string) string { return "" }})
+	if code != 0 || time.Since(start) > 5*time.Second {
+		t.Fatalf("Expected repl to stop when interrupted while waiting for input got %v after %v", code, time.Since(start))
+	}
+}
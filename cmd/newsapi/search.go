@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+//endpoints maps the command names used on the command line to NewsAPI endpoints
+var endpoints = map[string]newsapi.Endpoint{
+	"headlines":  newsapi.EndpointTopHeadlines,
+	"everything": newsapi.EndpointEverything,
+	"sources":    newsapi.EndpointSources,
+}
+
+func runSearch(e *env, args []string) error {
+	if len(args) == 0 {
+		return usageError("Expected list, run or add")
+	}
+	switch args[0] {
+	case "list":
+		return runSearchList(e, args[1:])
+	case "run":
+		return runSearchRun(e, args[1:])
+	case "add":
+		return runSearchAdd(e, args[1:])
+	}
+	return usageError(fmt.Sprintf("Unknown search command %v, expected list, run or add", args[0]))
+}
+
+//searchesFlag adds the -file flag for the saved search file
+func searchesFlag(e *env, fs *flag.FlagSet) *string {
+	def := ""
+	if dir := configDir(e); dir != "" {
+		def = filepath.Join(dir, "searches.json")
+	}
+	return fs.String("file", def, "Saved search file")
+}
+
+//loadSearches reads the saved search file at path, a missing file has no searches
+func loadSearches(path string) (*newsapi.SavedSearches, error) {
+	if path == "" {
+		return nil, usageError("No saved search file, use -file")
+	}
+	s, err := newsapi.LoadSearches(path)
+	if os.IsNotExist(err) {
+		return &newsapi.SavedSearches{}, nil
+	}
+	return s, err
+}
+
+func runSearchList(e *env, args []string) error {
+	fs := flag.NewFlagSet("newsapi search list", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	file := searchesFlag(e, fs)
+	tag := fs.String("tag", "", "Only list searches with this tag")
+	format := fs.String("format", formatTable, "Output format: "+strings.Join(formats, ", "))
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errReported
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	s, err := loadSearches(*file)
+	if err != nil {
+		return err
+	}
+	l := s.Tagged(*tag)
+	switch *format {
+	case formatJSON:
+		return writeJSON(e.stdout, newsapi.SavedSearches{Searches: l})
+	case formatNDJSON:
+		for _, ss := range l {
+			if err := writeNDJSON(e.stdout, ss); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	rows := make([][]string, len(l))
+	for i, ss := range l {
+		rows[i] = []string{ss.Name, string(ss.Endpoint), strings.Join(ss.Tags, ","), ss.Description}
+	}
+	if *format == formatCSV {
+		return writeCSV(e.stdout, []string{"name", "endpoint", "tags", "description"}, rows)
+	}
+	return writeTable(e.stdout, []string{"NAME", "ENDPOINT", "TAGS", "DESCRIPTION"}, rows)
+}
+
+func runSearchRun(e *env, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return usageError("Expected the name of a saved search, for example newsapi search run uk-tech")
+	}
+	name := args[0]
+	fs, g, _ := newFlagSet(e, "search run")
+	file := searchesFlag(e, fs)
+	if err := parse(fs, g, args[1:], nil); err != nil {
+		return err
+	}
+	s, err := loadSearches(*file)
+	if err != nil {
+		return err
+	}
+	if ss, ok := s.Get(name); ok {
+		header := articleHeader
+		if ss.Endpoint == newsapi.EndpointSources {
+			header = sourceHeader
+		}
+		if err := g.out.check(header); err != nil {
+			return err
+		}
+	}
+	c, err := g.client(e)
+	if err != nil {
+		return err
+	}
+	r, err := s.Run(c, name)
+	if err != nil {
+		return dryRun(e, err)
+	}
+	if r.Sources != nil {
+		return g.out.sources(e.stdout, *r.Sources)
+	}
+	return g.out.articles(e.stdout, *r.Articles)
+}
+
+func runSearchAdd(e *env, args []string) error {
+	if len(args) < 2 || strings.HasPrefix(args[0], "-") {
+		return usageError("Expected a name and an endpoint, for example newsapi search add uk-tech headlines -country gb")
+	}
+	name, endpoint := args[0], endpoints[args[1]]
+	fs := flag.NewFlagSet("newsapi search add", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	p := newParamFlags()
+	switch endpoint {
+	case newsapi.EndpointTopHeadlines:
+		headlinesFlags(fs, p)
+	case newsapi.EndpointEverything:
+		everythingFlags(fs, p)
+	case newsapi.EndpointSources:
+		sourcesFlags(fs, p)
+	default:
+		return usageError(fmt.Sprintf("Unknown endpoint %v, expected headlines, everything or sources", args[1]))
+	}
+	file := searchesFlag(e, fs)
+	description := fs.String("description", "", "What the search is for")
+	tags := fs.String("tags", "", "Comma separated tags")
+	replace := fs.Bool("replace", false, "Replace any saved search with the same name")
+	if err := fs.Parse(args[2:]); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errReported
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Sprintf("Unexpected argument %v", fs.Arg(0)))
+	}
+	s, err := loadSearches(*file)
+	if err != nil {
+		return err
+	}
+	ss := newsapi.SavedSearch{
+		Name:        name,
+		Description: *description,
+		Endpoint:    endpoint,
+		Parameters:  p.parameters(),
+	}
+	for _, t := range strings.Split(*tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			ss.Tags = append(ss.Tags, t)
+		}
+	}
+	if err := s.Add(ss, *replace); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*file), 0700); err != nil {
+		return err
+	}
+	return s.Save(*file)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSearchAddListRun(t *testing.T) {
+	s, last := testServer(t)
+	home := t.TempDir()
+	vars := map[string]string{"HOME": home}
+
+	steps := []struct {
+		testName string
+		args     []string
+		code     int
+		stdout   string
+		stderr   string
+	}{
+		{"List empty", []string{"search", "list"}, 0, "NAME", ""},
+		{"Add headlines", []string{"search", "add", "uk-tech", "headlines", "-country", "gb", "-category", "technology", "-tags", "uk, tech", "-description", "UK tech"}, 0, "", ""},
+		{"Add everything", []string{"search", "add", "rust", "everything", "-q", "rust", "-page-size", "10", "-tags", "tech"}, 0, "", ""},
+		{"Add sources", []string{"search", "add", "english", "sources", "-language", "en"}, 0, "", ""},
+		{"Add duplicate", []string{"search", "add", "rust", "everything", "-q", "go"}, 1, "", "Saved search rust already exists"},
+		{"Replace", []string{"search", "add", "rust", "everything", "-q", "rust", "-page-size", "5", "-tags", "tech", "-replace"}, 0, "", ""},
+		{"Add invalid", []string{"search", "add", "bad", "headlines", "-country", "xx"}, 1, "", "Unsupported country code xx"},
+		{"Add unknown endpoint", []string{"search", "add", "bad", "other"}, 2, "", "Unknown endpoint other"},
+		{"List", []string{"search", "list"}, 0, "uk-tech  top-headlines  uk,tech  UK tech", ""},
+		{"List tag", []string{"search", "list", "-tag", "tech", "-format", "csv"}, 0, "name,endpoint,tags,description\nuk-tech,top-headlines,\"uk,tech\",UK tech\nrust,everything,tech,\n", ""},
+		{"Run headlines", []string{"search", "run", "uk-tech", "-key", "abc", "-base-url", s.URL}, 0, "PUBLISHED", ""},
+		{"Run sources", []string{"search", "run", "english", "-key", "abc", "-base-url", s.URL, "-format", "csv"}, 0, "id,name,description", ""},
+		{"Run missing", []string{"search", "run", "missing", "-key", "abc", "-base-url", s.URL}, 1, "", "No saved search called missing"},
+		{"Run without name", []string{"search", "run", "-key", "abc"}, 2, "", "Expected the name of a saved search"},
+		{"Unknown subcommand", []string{"search", "delete"}, 2, "", "Unknown search command delete"},
+	}
+	for _, v := range steps {
+		t.Run(v.testName, func(t *testing.T) {
+			stdout, stderr, code := runCmd(v.args, vars)
+			if code != v.code {
+				t.Fatalf("Expected exit code %v got %v: %v", v.code, code, stderr)
+			}
+			if !strings.Contains(stdout, v.stdout) {
+				t.Fatalf("Expected stdout to contain '%v' got '%v'", v.stdout, stdout)
+			}
+			if !strings.Contains(stderr, v.stderr) {
+				t.Fatalf("Expected stderr to contain '%v' got '%v'", v.stderr, stderr)
+			}
+		})
+	}
+
+	runCmd([]string{"search", "run", "rust", "-key", "abc", "-base-url", s.URL}, vars)
+	if last.URL.RawQuery != "pageSize=5&q=rust" {
+		t.Fatalf("Expected replaced search to be run got '%v'", last.URL.RawQuery)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(home, ".config", "newsapi", "searches.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"pageSize": 5`) {
+		t.Fatalf("Unexpected saved search file %s", b)
+	}
+}
+
+func TestSearchInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "searches.json")
+	ioutil.WriteFile(path, []byte(`{"searches": [{"name": "a", "endpoint": "sources", "parameters": {"q": "x"}}]}`), 0600)
+	_, stderr, code := runCmd([]string{"search", "list", "-file", path}, nil)
+	if code != 1 || !strings.Contains(stderr, "Invalid parameter q") {
+		t.Fatalf("Expected invalid file error got %v '%v'", code, stderr)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+func runSnapshot(e *env, args []string) error {
+	fs, g, p := newFlagSet(e, "snapshot")
+	headlinesFlags(fs, p)
+	out := fs.String("o", "", "File to write the snapshot to (default headlines-<time>.json in the current directory)")
+	if err := parse(fs, g, args, nil); err != nil {
+		return err
+	}
+	c, err := g.client(e)
+	if err != nil {
+		return err
+	}
+	s, err := c.SnapshotTopHeadlines(p.parameters())
+	if err != nil {
+		return dryRun(e, err)
+	}
+	path := *out
+	if path == "" {
+		path = "headlines-" + s.Taken.Format("20060102T150405Z") + ".json"
+	}
+	if err := s.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Saved %v articles to %v\n", len(s.Articles), path)
+	return nil
+}
+
+func runDiff(e *env, args []string) error {
+	fs := flag.NewFlagSet("newsapi diff", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	format := fs.String("format", formatTable, "Output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errReported
+	}
+	if fs.NArg() != 2 {
+		return usageError("Expected two snapshot files, for example newsapi diff before.json after.json")
+	}
+	if *format != formatTable && *format != formatJSON {
+		return usageError(fmt.Sprintf("Unsupported format %v, expected table or json", *format))
+	}
+	older, err := newsapi.LoadSnapshot(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	newer, err := newsapi.LoadSnapshot(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	d := newsapi.DiffSnapshots(older, newer)
+	if *format == formatJSON {
+		return writeJSON(e.stdout, d)
+	}
+	fmt.Fprintf(e.stdout, "%v articles at %v, %v articles at %v\n", len(older.Articles), snapshotTime(older), len(newer.Articles), snapshotTime(newer))
+	if d.Empty() {
+		fmt.Fprintln(e.stdout, "No changes")
+		return nil
+	}
+	var rows [][]string
+	for _, a := range d.Added {
+		rows = append(rows, []string{"added", strconv.Itoa(a.Rank), a.Article.Source.Name, truncate(cell(a.Article.Title), maxTitle)})
+	}
+	for _, a := range d.Removed {
+		rows = append(rows, []string{"removed", strconv.Itoa(a.Rank), a.Article.Source.Name, truncate(cell(a.Article.Title), maxTitle)})
+	}
+	for _, m := range d.Moved {
+		rows = append(rows, []string{"moved", fmt.Sprintf("%v -> %v", m.From, m.To), m.Article.Source.Name, truncate(cell(m.Article.Title), maxTitle)})
+	}
+	for _, r := range d.Retitled {
+		rows = append(rows, []string{"retitled", strconv.Itoa(r.Rank), r.Article.Source.Name, cell(r.From) + " -> " + cell(r.Article.Title)})
+	}
+	return writeTable(e.stdout, []string{"CHANGE", "RANK", "SOURCE", "TITLE"}, rows)
+}
+
+func snapshotTime(s *newsapi.Snapshot) string {
+	return s.Taken.Format("2006-01-02 15:04")
+}
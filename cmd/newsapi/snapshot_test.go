@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	s, last := testServer(t)
+	dir := t.TempDir()
+	before := filepath.Join(dir, "before.json")
+	stdout, stderr, code := runCmd([]string{"snapshot", "-key", "abc", "-base-url", s.URL, "-country", "gb", "-o", before}, nil)
+	if code != 0 || stdout != "Saved 20 articles to "+before+"\n" {
+		t.Fatalf("Unexpected snapshot output %v '%v' '%v'", code, stdout, stderr)
+	}
+	if last.URL.RawQuery != "country=gb" {
+		t.Fatalf("Unexpected query %v", last.URL.RawQuery)
+	}
+
+	older, err := newsapi.LoadSnapshot(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	older.Taken = time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	older.Save(before)
+	newer := *older
+	newer.Taken = older.Taken.Add(4 * time.Hour)
+	newer.Articles = append([]newsapi.Article{{Source: newsapi.Source{Name: "CNN"}, Title: "Breaking", URL: "https://cnn.com/breaking"}}, older.Articles[:3]...)
+	newer.Articles[1], newer.Articles[2] = newer.Articles[2], newer.Articles[1]
+	newer.Articles[3].Title = "Edited title"
+	after := filepath.Join(dir, "after.json")
+	newer.Save(after)
+
+	tt := []struct {
+		testName string
+		args     []string
+		code     int
+		stdout   string
+		stderr   string
+	}{
+		{"Table", []string{before, after}, 0, "20 articles at 2024-01-02 08:00, 4 articles at 2024-01-02 12:00\nCHANGE    RANK    SOURCE               TITLE\nadded     1       CNN                  Breaking\nremoved   4       CBS News             Raging NorCal wildfire turns deadly", ""},
+		{"Moved", []string{before, after}, 0, "moved     1 -> 3  Bustle.com           11 Meditations", ""},
+		{"Retitled", []string{before, after}, 0, "retitled  4       NBC News             Raging wildfire kills one, threatens Northern California city of Redding -> Edited title\n", ""},
+		{"Unchanged", []string{before, before}, 0, "No changes\n", ""},
+		{"JSON", []string{"-format", "json", before, after}, 0, `"from": "Raging wildfire kills one`, ""},
+		{"One file", []string{before}, 2, "", "Expected two snapshot files"},
+		{"Unsupported format", []string{"-format", "csv", before, after}, 2, "", "Unsupported format csv"},
+		{"Missing file", []string{before, filepath.Join(dir, "missing.json")}, 1, "", "no such file"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			stdout, stderr, code := runCmd(append([]string{"diff"}, v.args...), nil)
+			if code != v.code || !strings.Contains(stdout, v.stdout) || !strings.Contains(stderr, v.stderr) {
+				t.Fatalf("Expected %v '%v' '%v' got %v '%v' '%v'", v.code, v.stdout, v.stderr, code, stdout, stderr)
+			}
+		})
+	}
+
+	stdout, _, _ = runCmd([]string{"diff", "-format", "json", before, after}, nil)
+	var d newsapi.SnapshotDiff
+	if err := json.Unmarshal([]byte(stdout), &d); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Added) != 1 || len(d.Removed) != 17 || len(d.Moved) != 1 || len(d.Retitled) != 1 {
+		t.Fatalf("Unexpected diff %+v", d)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//templateFuncs are the helper functions available to -template
+//
+//	date "2006-01-02" .PublishedAt   formats a NewsAPI timestamp with a Go time layout
+//	ago .PublishedAt                 how long ago a timestamp was, for example 3h
+//	truncate 60 .Title               shortens text to at most 60 characters
+//	domain .URL                      the host of a URL without any www. prefix
+//	json .                           the value as JSON
+var templateFuncs = template.FuncMap{
+	"date":     formatDate,
+	"ago":      ago,
+	"truncate": truncateFunc,
+	"domain":   domain,
+	"json":     toJSON,
+}
+
+//truncateFunc is truncate for templates, where the length comes first so it can be piped into
+func truncateFunc(n int, s string) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("Expected truncate length of at least 0 got %d", n)
+	}
+	return truncate(s, n), nil
+}
+
+//now is replaced in tests
+var now = time.Now
+
+//parseTime parses a NewsAPI timestamp
+func parseTime(s string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, s)
+	return t, err == nil
+}
+
+//formatDate formats the timestamp s with layout, s is returned unchanged when it can't be parsed
+func formatDate(layout, s string) string {
+	t, ok := parseTime(s)
+	if !ok {
+		return s
+	}
+	return t.Format(layout)
+}
+
+//ago returns how long ago the timestamp s was in the largest whole unit, or an empty string when it can't be parsed
+func ago(s string) string {
+	t, ok := parseTime(s)
+	if !ok {
+		return ""
+	}
+	d := now().Sub(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%dd", d/(24*time.Hour))
+}
+
+//domain returns the host of rawURL without a leading www.
+func domain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	tt := []struct {
+		testName string
+		fn       func() string
+		expected string
+	}{
+		{"Date", func() string { return formatDate("02 Jan 2006 15:04", "2024-01-02T08:30:00Z") }, "02 Jan 2024 08:30"},
+		{"Date unparseable", func() string { return formatDate("2006", "yesterday") }, "yesterday"},
+		{"Ago seconds", func() string { return ago("2024-01-03T11:59:30Z") }, "now"},
+		{"Ago minutes", func() string { return ago("2024-01-03T11:15:00Z") }, "45m"},
+		{"Ago hours", func() string { return ago("2024-01-02T08:30:00Z") }, "27h"},
+		{"Ago days", func() string { return ago("2023-12-25T12:00:00Z") }, "9d"},
+		{"Ago unparseable", func() string { return ago("") }, ""},
+		{"Domain", func() string { return domain("https://www.bbc.co.uk/news/1") }, "bbc.co.uk"},
+		{"Domain port", func() string { return domain("http://example.com:8080/a") }, "example.com"},
+		{"Domain invalid", func() string { return domain("://") }, ""},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			if r := v.fn(); r != v.expected {
+				t.Fatalf("Expected '%v' got '%v'", v.expected, r)
+			}
+		})
+	}
+}
+
+func TestOutputOptions(t *testing.T) {
+	articles := newsapi.ArticleResults{Articles: []newsapi.Article{
+		{Source: newsapi.Source{Name: "BBC News"}, Title: "A long title about something", URL: "https://www.bbc.co.uk/a", PublishedAt: "2024-01-02T08:30:00Z"},
+		{Source: newsapi.Source{Name: "CNN"}, Title: "Short", URL: "https://cnn.com/b", PublishedAt: "2024-01-01T10:00:00Z"},
+	}}
+	sources := newsapi.SourceResults{Source: []newsapi.Source{{ID: "bbc-news", Name: "BBC News", URL: "https://www.bbc.co.uk"}}}
+
+	tt := []struct {
+		testName string
+		format   string
+		fields   string
+		tmpl     string
+		sources  bool
+		expected string
+	}{
+		{"Template", formatTable, "", `{{date "2006-01-02" .PublishedAt}} {{domain .URL}} {{truncate 10 .Title}}`, false, "2024-01-02 bbc.co.uk A long ...\n2024-01-01 cnn.com Short\n"},
+		{"Template short truncate", formatTable, "", `{{truncate 2 .Title}}`, false, "A \nSh\n"},
+		{"Template newline", formatTable, "", "{{.Source.Name}}\n", false, "BBC News\nCNN\n"},
+		{"Template JSON", formatJSON, "", `{{json .Source}}`, false, `{"id":"","name":"BBC News"}` + "\n" + `{"id":"","name":"CNN"}` + "\n"},
+		{"Template sources", formatTable, "", `{{.ID}} {{domain .URL}}`, true, "bbc-news bbc.co.uk\n"},
+		{"Table fields", formatTable, "sourceName,url", "", false, "SOURCE    URL\nBBC News  https://www.bbc.co.uk/a\nCNN       https://cnn.com/b\n"},
+		{"CSV fields", formatCSV, "title, publishedAt", "", false, "title,publishedAt\nA long title about something,2024-01-02T08:30:00Z\nShort,2024-01-01T10:00:00Z\n"},
+		{"Source fields", formatCSV, "name,id", "", true, "name,id\nBBC News,bbc-news\n"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			o, err := newOutput(v.format, v.fields, v.tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if v.sources {
+				err = o.sources(&buf, sources)
+			} else {
+				err = o.articles(&buf, articles)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != v.expected {
+				t.Fatalf("Expected '%v' got '%v'", v.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestOutputErrors(t *testing.T) {
+	tt := []struct {
+		testName string
+		args     []string
+		code     int
+		stderr   string
+	}{
+		{"Unknown field", []string{"headlines", "-key", "abc", "-fields", "title,nope"}, 2, "Unknown field nope"},
+		{"Invalid template", []string{"headlines", "-key", "abc", "-template", "{{.Title"}, 2, "Invalid template"},
+		{"Field for other type", []string{"sources", "-fields", "title"}, 2, "Field title isn't available here"},
+		{"Template error", []string{"sources", "-template", "{{.Nope}}"}, 1, "can't evaluate field Nope"},
+		{"Negative truncate", []string{"sources", "-template", "{{truncate -1 .Name}}"}, 1, "Expected truncate length of at least 0 got -1"},
+	}
+	s, _ := testServer(t)
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			_, stderr, code := runCmd(append(v.args, "-key", "abc", "-base-url", s.URL), nil)
+			if code != v.code || !strings.Contains(stderr, v.stderr) {
+				t.Fatalf("Expected %v '%v' got %v '%v'", v.code, v.stderr, code, stderr)
+			}
+		})
+	}
+}
+
+func TestTemplateCommand(t *testing.T) {
+	s, _ := testServer(t)
+	stdout, stderr, code := runCmd([]string{"everything", "-q", "bitcoin", "-key", "abc", "-base-url", s.URL, "-template", "{{domain .URL}}"}, nil)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 got %v: %v", code, stderr)
+	}
+	if !strings.HasPrefix(stdout, "youbrandinc.com\n") || strings.Count(stdout, "\n") != 20 {
+		t.Fatalf("Unexpected output '%v'", stdout)
+	}
+}
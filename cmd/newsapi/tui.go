@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+//tuiPageSize is how many articles the tui asks for on each page
+const tuiPageSize = 10
+
+//clearScreen moves the cursor to the top left and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+const tuiHelp = `Commands:
+  c <country>    Top headlines for a country, c on its own clears the country
+  g <category>   Top headlines for a category, g on its own clears the category
+  / <query>      Search every article
+  r <name>       Run a saved search
+  n, p           Next or previous page
+  <number>       Show an article and mark it read
+  m <number>     Toggle whether an article is read
+  l              Show the list again
+  ?              Show this help
+  q              Quit
+Unread articles are marked with *, replies answered from the cache don't use quota`
+
+//tui is the state of an interactive browsing session
+type tui struct {
+	e        *env
+	c        *newsapi.Client
+	searches string //Saved search file
+	readFile string //File read articles are kept in, empty to only remember them for the session
+	read     map[string]bool
+	clear    bool //Clear the screen before showing a list
+
+	title    string
+	endpoint newsapi.Endpoint
+	params   map[string]interface{}
+	country  string
+	category string
+	page     int
+	results  newsapi.ArticleResults
+}
+
+func runTUI(e *env, args []string) error {
+	e, stop := interruptible(e)
+	defer stop()
+	fs, g, _ := newFlagSet(e, "tui")
+	t := &tui{e: e, page: 1, clear: isTerminal(e.stdout)}
+	fs.StringVar(&t.country, "country", "", "2-letter country code to show headlines for at start")
+	fs.StringVar(&t.category, "category", "", "Category of news to show headlines for at start")
+	searches := searchesFlag(e, fs)
+	readDef := ""
+	if dir := configDir(e); dir != "" {
+		readDef = filepath.Join(dir, "read.json")
+	}
+	fs.StringVar(&t.readFile, "read-file", readDef, "File read articles are kept in")
+	ttl := fs.Duration("cache", 5*time.Minute, "How long replies are reused before asking NewsAPI again")
+	if err := parse(fs, g, args, nil); err != nil {
+		return err
+	}
+	t.searches = *searches
+	c, err := g.client(e)
+	if err != nil {
+		return err
+	}
+	if *ttl > 0 {
+		newsapi.WithCache(*ttl)(c)
+	}
+	t.c = c
+	if t.read, err = loadRead(t.readFile); err != nil {
+		return err
+	}
+	if t.country != "" || t.category != "" {
+		t.headlines()
+	} else {
+		fmt.Fprintln(e.stdout, tuiHelp)
+	}
+	return t.loop()
+}
+
+//loop reads commands from stdin until q, the end of the input or an interrupt
+func (t *tui) loop() error {
+	in := newLineReader(t.e.stdin)
+	for {
+		fmt.Fprint(t.e.stdout, "> ")
+		line, ok := in.next(t.e.ctx)
+		if !ok {
+			fmt.Fprintln(t.e.stdout)
+			if t.e.ctx.Err() != nil {
+				return nil
+			}
+			return in.err
+		}
+		cmd, arg := strings.TrimSpace(line), ""
+		if i := strings.IndexAny(cmd, " \t"); i >= 0 {
+			cmd, arg = cmd[:i], strings.TrimSpace(cmd[i+1:])
+		} else if strings.HasPrefix(cmd, "/") && len(cmd) > 1 {
+			cmd, arg = "/", cmd[1:]
+		}
+		if cmd == "q" {
+			return nil
+		}
+		t.command(cmd, arg)
+	}
+}
+
+//command runs a single tui command, mistakes are reported and browsing carries on
+func (t *tui) command(cmd, arg string) {
+	switch cmd {
+	case "":
+	case "?", "h", "help":
+		fmt.Fprintln(t.e.stdout, tuiHelp)
+	case "c":
+		t.country = arg
+		t.headlines()
+	case "g":
+		t.category = arg
+		t.headlines()
+	case "/":
+		if arg == "" {
+			t.message("Expected a query, for example / golang")
+			return
+		}
+		t.show(fmt.Sprintf("Everything matching %q", arg), newsapi.EndpointEverything, map[string]interface{}{"q": arg})
+	case "r":
+		t.search(arg)
+	case "n":
+		if t.endpoint == "" || t.page*tuiPageSize >= int(t.results.TotalResults) {
+			t.message("No more pages")
+			return
+		}
+		t.fetch(t.page + 1)
+	case "p":
+		if t.page <= 1 {
+			t.message("Already on the first page")
+			return
+		}
+		t.fetch(t.page - 1)
+	case "l":
+		t.list()
+	case "m":
+		if a, ok := t.article(arg); ok {
+			t.mark(a, !t.read[newsapi.ArticleKey(a)])
+			t.list()
+		}
+	default:
+		if a, ok := t.article(cmd); ok {
+			t.mark(a, true)
+			t.details(a)
+		}
+	}
+}
+
+//headlines shows the top headlines for the current country and category
+func (t *tui) headlines() {
+	p := make(map[string]interface{})
+	var filters []string
+	if t.country != "" {
+		p["country"] = t.country
+		filters = append(filters, "country "+t.country)
+	}
+	if t.category != "" {
+		p["category"] = t.category
+		filters = append(filters, "category "+t.category)
+	}
+	if len(p) == 0 {
+		t.message("Expected a country or category, for example c gb or g technology")
+		return
+	}
+	t.show("Top headlines for "+strings.Join(filters, ", "), newsapi.EndpointTopHeadlines, p)
+}
+
+//search shows the results of the saved search called name
+func (t *tui) search(name string) {
+	if name == "" {
+		t.message("Expected the name of a saved search, for example r uk-tech")
+		return
+	}
+	s, err := loadSearches(t.searches)
+	if err != nil {
+		t.message(err.Error())
+		return
+	}
+	ss, ok := s.Get(name)
+	if !ok {
+		t.message(fmt.Sprintf("No saved search called %v", name))
+		return
+	}
+	if ss.Endpoint == newsapi.EndpointSources {
+		t.message(fmt.Sprintf("Saved search %v lists sources, only article searches can be browsed", name))
+		return
+	}
+	r, err := ss.Request()
+	if err != nil {
+		t.message(err.Error())
+		return
+	}
+	title := "Saved search " + name
+	if ss.Description != "" {
+		title += ": " + ss.Description
+	}
+	t.show(title, r.Endpoint, r.Parameters)
+}
+
+//show makes the request the current view and shows its first page
+func (t *tui) show(title string, endpoint newsapi.Endpoint, params map[string]interface{}) {
+	t.title, t.endpoint, t.params = title, endpoint, params
+	t.results = newsapi.ArticleResults{}
+	t.fetch(1)
+}
+
+//fetch requests page of the current view and lists it
+func (t *tui) fetch(page int) {
+	p := make(map[string]interface{}, len(t.params)+2)
+	for k, v := range t.params {
+		p[k] = v
+	}
+	p["page"] = page
+	p["pageSize"] = tuiPageSize
+	var r newsapi.ArticleResults
+	var err error
+	if t.endpoint == newsapi.EndpointEverything {
+		r, err = t.c.GetEverythingContext(t.e.ctx, p)
+	} else {
+		r, err = t.c.GetTopHeadlinesContext(t.e.ctx, p)
+	}
+	if err != nil {
+		t.message(err.Error())
+		return
+	}
+	t.page, t.results = page, r
+	t.list()
+}
+
+//list shows the current page of articles
+func (t *tui) list() {
+	if t.endpoint == "" {
+		t.message("Nothing to list yet, try c gb or / golang")
+		return
+	}
+	w := t.e.stdout
+	if t.clear {
+		io.WriteString(w, clearScreen)
+	}
+	pages := (int(t.results.TotalResults) + tuiPageSize - 1) / tuiPageSize
+	if pages < 1 {
+		pages = 1
+	}
+	status := fmt.Sprintf("page %v of %v, %v results", t.page, pages, t.results.TotalResults)
+	if t.results.Response != nil && t.results.Response.FromCache {
+		status += " (cached)"
+	}
+	fmt.Fprintf(w, "%v - %v\n", t.title, status)
+	if len(t.results.Articles) == 0 {
+		fmt.Fprintln(w, "No articles")
+		return
+	}
+	rows := make([][]string, len(t.results.Articles))
+	for i, a := range t.results.Articles {
+		mark := "*"
+		if t.read[newsapi.ArticleKey(a)] {
+			mark = " "
+		}
+		rows[i] = []string{strconv.Itoa(i + 1), mark, ago(a.PublishedAt), a.Source.Name, truncate(cell(a.Title), maxTitle)}
+	}
+	writeTable(w, nil, rows)
+}
+
+//details shows every field of a
+func (t *tui) details(a newsapi.Article) {
+	w := t.e.stdout
+	if t.clear {
+		io.WriteString(w, clearScreen)
+	}
+	fmt.Fprintln(w, cell(a.Title))
+	byline := []string{a.Source.Name}
+	if a.Author != "" {
+		byline = append(byline, a.Author)
+	}
+	if a.PublishedAt != "" {
+		byline = append(byline, formatDate("02 Jan 2006 15:04", a.PublishedAt))
+	}
+	fmt.Fprintln(w, strings.Join(byline, " | "))
+	fmt.Fprintln(w, a.URL)
+	for _, s := range []string{a.Description, a.Content} {
+		if s = strings.TrimSpace(s); s != "" {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, s)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Enter l to go back to the list")
+}
+
+//article returns the article on the current page numbered n
+func (t *tui) article(n string) (newsapi.Article, bool) {
+	i, err := strconv.Atoi(n)
+	if err != nil {
+		t.message(fmt.Sprintf("Unknown command %v, enter ? for help", n))
+		return newsapi.Article{}, false
+	}
+	if i < 1 || i > len(t.results.Articles) {
+		t.message(fmt.Sprintf("No article %v on this page", i))
+		return newsapi.Article{}, false
+	}
+	return t.results.Articles[i-1], true
+}
+
+//mark records whether a has been read and saves the read file
+func (t *tui) mark(a newsapi.Article, read bool) {
+	k := newsapi.ArticleKey(a)
+	if t.read[k] == read {
+		return
+	}
+	if read {
+		t.read[k] = true
+	} else {
+		delete(t.read, k)
+	}
+	if err := saveRead(t.readFile, t.read); err != nil {
+		t.message(fmt.Sprintf("Unable to save read articles: %v", err))
+	}
+}
+
+func (t *tui) message(s string) {
+	fmt.Fprintln(t.e.stdout, s)
+}
+
+//loadRead reads the articles marked read from path, a missing file has none
+func loadRead(path string) (map[string]bool, error) {
+	read := make(map[string]bool)
+	if path == "" {
+		return read, nil
+	}
+	d, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return read, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	if err := json.Unmarshal(d, &keys); err != nil {
+		return nil, fmt.Errorf("Invalid read file %v: %v", path, err)
+	}
+	for _, k := range keys {
+		read[k] = true
+	}
+	return read, nil
+}
+
+//saveRead writes the articles marked read to path
+func saveRead(path string, read map[string]bool) error {
+	if path == "" {
+		return nil
+	}
+	keys := make([]string, 0, len(read))
+	for k := range read {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	d, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, d, 0600)
+}
+
+//isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+//lineReader reads lines in the background so an interrupt doesn't have to wait for the next line
+type lineReader struct {
+	lines chan string
+	err   error //Set before lines is closed
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	l := &lineReader{lines: make(chan string)}
+	go func() {
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			l.lines <- s.Text()
+		}
+		l.err = s.Err()
+		close(l.lines)
+	}()
+	return l
+}
+
+//next returns the next line, ok is false at the end of the input or once ctx is done
+func (l *lineReader) next(ctx context.Context) (string, bool) {
+	select {
+	case line, ok := <-l.lines:
+		return line, ok
+	case <-ctx.Done():
+		return "", false
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTUI(t *testing.T) {
+	s, last := testServer(t)
+	home := t.TempDir()
+	vars := map[string]string{"HOME": home}
+	runCmd([]string{"search", "add", "uk-tech", "headlines", "-country", "gb", "-category", "technology", "-description", "UK tech"}, vars)
+	runCmd([]string{"search", "add", "english", "sources", "-language", "en"}, vars)
+
+	tt := []struct {
+		testName string
+		args     []string
+		input    string
+		contains []string
+		query    string
+	}{
+		{"Help", nil, "?\nq\n", []string{"Commands:", "m <number>"}, ""},
+		{"Start with country", []string{"-country", "us"}, "q\n", []string{"Top headlines for country us - page 1 of 2, 20 results", "\n1   *  "}, "country=us&page=1&pageSize=10"},
+		{"Country and category", nil, "c gb\ng technology\n", []string{"Top headlines for country gb, category technology"}, "category=technology&country=gb&page=1&pageSize=10"},
+		{"Paging", nil, "c gb\nn\nn\np\n", []string{"page 2 of 2", "No more pages", "page 1 of 2, 20 results (cached)"}, "country=gb&page=2&pageSize=10"},
+		{"First page", nil, "/bitcoin\np\n", []string{`Everything matching "bitcoin" - page 1 of 16875`, "Already on the first page"}, "page=1&pageSize=10&q=bitcoin"},
+		{"Details", nil, "c gb\n2\n", []string{"North Korea hands over remains", "CBS News | ", "https://www.cbsnews.com/news/korean-war-soldiers-remains-returned-north-korea-today-2018-07-27/"}, ""},
+		{"Saved search", nil, "r uk-tech\n", []string{"Saved search uk-tech: UK tech - page 1"}, "category=technology&country=gb&page=1&pageSize=10"},
+		{"Saved sources search", nil, "r english\n", []string{"only article searches can be browsed"}, ""},
+		{"Missing saved search", nil, "r nope\n", []string{"No saved search called nope"}, ""},
+		{"API error", nil, "c xx\n", []string{"Unsupported country code xx"}, ""},
+		{"Nothing to list", nil, "l\nn\n", []string{"Nothing to list yet", "No more pages"}, ""},
+		{"Unknown command", nil, "c gb\nzz\n99\n", []string{"Unknown command zz", "No article 99 on this page"}, ""},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			last.URL = nil
+			args := append([]string{"tui", "-key", "abc", "-base-url", s.URL}, v.args...)
+			stdout, stderr, code := runCmdInput(context.Background(), args, vars, v.input)
+			if code != 0 {
+				t.Fatalf("Expected exit code 0 got %v: %v", code, stderr)
+			}
+			for _, c := range v.contains {
+				if !strings.Contains(stdout, c) {
+					t.Fatalf("Expected output to contain '%v' got '%v'", c, stdout)
+				}
+			}
+			if v.query != "" && (last.URL == nil || last.URL.RawQuery != v.query) {
+				t.Fatalf("Expected query '%v' got '%v'", v.query, last.URL)
+			}
+		})
+	}
+}
+
+func TestTUIRead(t *testing.T) {
+	s, _ := testServer(t)
+	home := t.TempDir()
+	vars := map[string]string{"HOME": home}
+	args := []string{"tui", "-key", "abc", "-base-url", s.URL, "-country", "gb"}
+
+	runCmdInput(context.Background(), args, vars, "1\nm 2\nm 3\nm 2\n")
+	b, err := ioutil.ReadFile(filepath.Join(home, ".config", "newsapi", "read.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `["https://www.bustle.com/p/11-meditations-to-help-you-destress-during-the-july-2018-blood-moon-9887213","https://www.nbcnews.com/news/weather/smoke-california-s-huge-cranston-fire-creates-its-own-weather-n895081"]`
+	if string(b) != expected {
+		t.Fatalf("Expected read file '%v' got '%v'", expected, string(b))
+	}
+
+	stdout, _, _ := runCmdInput(context.Background(), args, vars, "")
+	lines := strings.Split(stdout, "\n")
+	for i, expected := range []string{"1      ", "2   *  ", "3      "} {
+		if !strings.HasPrefix(lines[i+1], expected) {
+			t.Fatalf("Expected '%v' got '%v'", expected, lines[i+1])
+		}
+	}
+}
+
+func TestTUIInterrupt(t *testing.T) {
+	stdin, w := io.Pipe()
+	defer w.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var stdout bytes.Buffer
+	start := time.Now()
+	code := run([]string{"tui", "-key", "abc", "-read-file", ""}, &env{ctx: ctx, stdin: stdin, stdout: &stdout, stderr: ioutil.Discard, getenv: func(string) string { return "" }})
+	if code != 0 || time.Since(start) > 5*time.Second {
+		t.Fatalf("Expected tui to stop when interrupted while waiting for input got %v after %v", code, time.Since(start))
+	}
+}
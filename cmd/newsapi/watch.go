@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+//maxSeen is how many article URLs watch remembers, the oldest are forgotten first
+const maxSeen = 10000
+
+//seenURLs remembers which articles have already been reported
+type seenURLs struct {
+	set   map[string]struct{}
+	order []string
+	max   int
+}
+
+func newSeenURLs(max int) *seenURLs {
+	return &seenURLs{set: make(map[string]struct{}), max: max}
+}
+
+//add records a and reports whether it hadn't been seen before
+func (s *seenURLs) add(a newsapi.Article) bool {
+	k := newsapi.ArticleKey(a)
+	if _, ok := s.set[k]; ok {
+		return false
+	}
+	s.set[k] = struct{}{}
+	s.order = append(s.order, k)
+	if len(s.order) > s.max {
+		delete(s.set, s.order[0])
+		s.order = s.order[1:]
+	}
+	return true
+}
+
+//watchFlags are the flags specific to the watch command
+type watchFlags struct {
+	interval     time.Duration
+	exec         string
+	webhook      string
+	skipExisting bool
+}
+
+func runWatch(e *env, args []string) error {
+	if len(args) == 0 || (args[0] != "headlines" && args[0] != "everything") {
+		return usageError("Expected headlines or everything, for example newsapi watch headlines -country gb")
+	}
+	endpoint := args[0]
+	fs, g, p := newFlagSet(e, "watch "+endpoint)
+	if endpoint == "headlines" {
+		headlinesFlags(fs, p)
+	} else {
+		everythingFlags(fs, p)
+	}
+	var w watchFlags
+	fs.DurationVar(&w.interval, "interval", 5*time.Minute, "Time between requests")
+	fs.StringVar(&w.exec, "exec", "", "Command to run for each new article with the article as JSON on stdin")
+	fs.StringVar(&w.webhook, "webhook", "", "URL to POST each new article to as JSON")
+	fs.BoolVar(&w.skipExisting, "skip-existing", false, "Only report articles that appear after the first request")
+	if err := parse(fs, g, args[1:], articleHeader); err != nil {
+		return err
+	}
+	if w.interval <= 0 {
+		return usageError("Interval must be greater than zero")
+	}
+	c, err := g.client(e)
+	if err != nil {
+		return err
+	}
+	e, stop := interruptible(e)
+	defer stop()
+	poll := func() (newsapi.ArticleResults, error) {
+		if endpoint == "headlines" {
+			return c.GetTopHeadlinesContext(e.ctx, p.parameters())
+		}
+		return c.GetEverythingContext(e.ctx, p.parameters())
+	}
+	hc := &http.Client{Timeout: g.timeout}
+	out := &articleStream{w: e.stdout, out: g.out}
+	seen := newSeenURLs(maxSeen)
+	for first := true; ; first = false {
+		r, err := poll()
+		if e.ctx.Err() != nil {
+			return nil
+		}
+		switch {
+		case err != nil && first:
+			return dryRun(e, err)
+		case err != nil && fatal(err):
+			return err
+		case err != nil:
+			fmt.Fprintf(e.stderr, "newsapi watch: %v\n", err)
+		default:
+			var fresh []newsapi.Article
+			//Articles are returned newest first, report them oldest first
+			for i := len(r.Articles) - 1; i >= 0; i-- {
+				if seen.add(r.Articles[i]) {
+					fresh = append(fresh, r.Articles[i])
+				}
+			}
+			if !(first && w.skipExisting) {
+				if err := w.report(e, hc, out, fresh); err != nil {
+					return err
+				}
+			}
+		}
+		select {
+		case <-e.ctx.Done():
+			return nil
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+//fatal reports whether a failed poll should stop watch rather than being retried
+func fatal(err error) bool {
+	var apiErr *newsapi.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code != "rateLimited"
+	}
+	return false
+}
+
+//report writes new articles and passes each one to the exec command and webhook
+//Failures of the command or webhook are printed but don't stop watch
+func (w *watchFlags) report(e *env, hc *http.Client, out *articleStream, articles []newsapi.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+	if err := out.write(articles); err != nil {
+		return err
+	}
+	for _, a := range articles {
+		b, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		if w.exec != "" {
+			if err := runExec(e, w.exec, b); err != nil {
+				fmt.Fprintf(e.stderr, "newsapi watch: exec %v: %v\n", a.URL, err)
+			}
+		}
+		if w.webhook != "" {
+			if err := postWebhook(e.ctx, hc, w.webhook, b); err != nil {
+				fmt.Fprintf(e.stderr, "newsapi watch: webhook %v: %v\n", a.URL, err)
+			}
+		}
+	}
+	return nil
+}
+
+//runExec runs command through the shell with body on stdin
+func runExec(e *env, command string, body []byte) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(e.ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(e.ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = e.stdout
+	cmd.Stderr = e.stderr
+	return cmd.Run()
+}
+
+//postWebhook POSTs body to url as JSON
+func postWebhook(ctx context.Context, hc *http.Client, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("Unexpected status %v", res.Status)
+	}
+	return nil
+}
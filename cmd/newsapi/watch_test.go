@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+)
+
+//watchServer replies to each poll with the next entry of replies, newest articles first
+//The last reply is repeated and cancel is called on the poll after it, so everything it returns has been reported
+func watchServer(t *testing.T, cancel context.CancelFunc, replies []string) *httptest.Server {
+	var mu sync.Mutex
+	n := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		reply := replies[len(replies)-1]
+		if n < len(replies) {
+			reply = replies[n]
+		} else {
+			cancel()
+		}
+		n++
+		switch reply {
+		case "invalid":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error","code":"apiKeyInvalid","message":"Your API key is invalid or incorrect."}`))
+			return
+		case "bad gateway":
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>Bad Gateway</html>"))
+			return
+		}
+		var o newsapi.ArticleResults
+		o.Status = "ok"
+		for _, u := range strings.Split(reply, ",") {
+			o.Articles = append(o.Articles, newsapi.Article{Title: "Article " + u, URL: "https://example.com/" + u})
+		}
+		json.NewEncoder(w).Encode(o)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestWatch(t *testing.T) {
+	tt := []struct {
+		testName string
+		replies  []string
+		args     []string
+		expected []string
+		code     int
+		stderr   string
+	}{
+		{"New articles", []string{"b,a", "c,b,a", "c,b,a"}, nil, []string{"a", "b", "c"}, 0, ""},
+		{"Skip existing", []string{"b,a", "d,c,b,a"}, []string{"-skip-existing"}, []string{"c", "d"}, 0, ""},
+		{"Transient error", []string{"a", "bad gateway", "b,a"}, nil, []string{"a", "b"}, 0, "Bad Gateway"},
+		{"Fatal error", []string{"a", "invalid", "b"}, nil, []string{"a"}, 1, "Your API key is invalid or incorrect."},
+		{"First request fails", []string{"invalid"}, nil, nil, 1, "Your API key is invalid or incorrect."},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			s := watchServer(t, cancel, v.replies)
+			args := append([]string{"watch", "everything", "-q", "test", "-key", "abc", "-base-url", s.URL, "-interval", "1ms", "-format", "ndjson"}, v.args...)
+			stdout, stderr, code := runCmdContext(ctx, args, nil)
+			if code != v.code {
+				t.Fatalf("Expected exit code %v got %v: %v", v.code, code, stderr)
+			}
+			if !strings.Contains(stderr, v.stderr) {
+				t.Fatalf("Expected stderr to contain '%v' got '%v'", v.stderr, stderr)
+			}
+			var got []string
+			dec := json.NewDecoder(strings.NewReader(stdout))
+			for dec.More() {
+				var a newsapi.Article
+				if err := dec.Decode(&a); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, strings.TrimPrefix(a.URL, "https://example.com/"))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(v.expected) {
+				t.Fatalf("Expected articles %v got %v", v.expected, got)
+			}
+		})
+	}
+}
+
+func TestWatchExecAndWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var posted []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a newsapi.Article
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected content type %v", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&a)
+		mu.Lock()
+		posted = append(posted, a.Title)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := watchServer(t, cancel, []string{"a", "b,a"})
+	out := filepath.Join(t.TempDir(), "out")
+	args := []string{"watch", "headlines", "-key", "abc", "-base-url", s.URL, "-interval", "1ms", "-exec", "cat >> " + out + "; echo >> " + out, "-webhook", hook.URL}
+	_, stderr, code := runCmdContext(ctx, args, nil)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 got %v: %v", code, stderr)
+	}
+	if fmt.Sprint(posted) != "[Article a Article b]" {
+		t.Fatalf("Unexpected webhook posts %v", posted)
+	}
+	b, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"title":"Article b"`) {
+		t.Fatalf("Unexpected exec input '%s'", b)
+	}
+}
+
+func TestWatchInterruptDuringRequest(t *testing.T) {
+	done := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer s.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, stderr, code := runCmdContext(ctx, []string{"watch", "headlines", "-key", "abc", "-base-url", s.URL, "-country", "gb", "-timeout", "10s"}, nil)
+	if code != 0 || time.Since(start) > 5*time.Second {
+		t.Fatalf("Expected watch to stop when interrupted during a request got %v after %v: %v", code, time.Since(start), stderr)
+	}
+}
+
+func TestWatchUsage(t *testing.T) {
+	tt := []struct {
+		testName string
+		args     []string
+		stderr   string
+	}{
+		{"No endpoint", []string{"watch"}, "Expected headlines or everything"},
+		{"Sources", []string{"watch", "sources"}, "Expected headlines or everything"},
+		{"Bad interval", []string{"watch", "headlines", "-interval", "0s"}, "Interval must be greater than zero"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			_, stderr, code := runCmd(v.args, nil)
+			if code != 2 || !strings.Contains(stderr, v.stderr) {
+				t.Fatalf("Expected usage error '%v' got %v '%v'", v.stderr, code, stderr)
+			}
+		})
+	}
+}
+
+func TestSeenURLs(t *testing.T) {
+	s := newSeenURLs(2)
+	a := newsapi.Article{URL: "a"}
+	b := newsapi.Article{URL: "b"}
+	c := newsapi.Article{Title: "c"}
+	if !s.add(a) || s.add(a) || !s.add(b) || !s.add(c) || s.add(c) {
+		t.Fatal("Unexpected duplicate detection")
+	}
+	if !s.add(a) {
+		t.Fatal("Expected oldest URL to be forgotten")
+	}
+}
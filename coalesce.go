@@ -0,0 +1,97 @@
+package newsapi
+
+import (
+	"context"
+	"sync"
+)
+
+//CoalesceStats reports how many upstream calls were made by a coalescing Client and how many were saved
+type CoalesceStats struct {
+	Calls  int64 //Requests that were sent upstream
+	Shared int64 //Requests that were answered with the result of another in-flight request
+}
+
+//call is a single upstream request that one or more callers are waiting on
+type call struct {
+	done    chan struct{} //Closed once val, res and err are set
+	val     []byte
+	res     *Response
+	err     error
+	dups    int
+	waiters int                //Callers still waiting, the request is cancelled when the last gives up
+	cancel  context.CancelFunc //Cancels the context fn runs with
+}
+
+//coalescer merges concurrent identical requests into a single upstream call
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*call
+	stats CoalesceStats
+}
+
+//WithCoalescing makes the Client share one upstream call between concurrent requests for the same URL and key
+func WithCoalescing() option {
+	return func(c *Client) {
+		c.coalescer = &coalescer{calls: make(map[string]*call)}
+	}
+}
+
+//do runs fn once for all concurrent callers using the same key
+//fn gets the values of the first caller's ctx but is only cancelled once every caller has given up waiting,
+//a caller whose ctx is done stops waiting and gets a TransportError
+//The returned bool is true when the result came from another caller's request
+func (g *coalescer) do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, *Response, error)) ([]byte, *Response, bool, error) {
+	g.mu.Lock()
+	cl, shared := g.calls[key]
+	if shared {
+		cl.dups++
+		cl.waiters++
+		g.stats.Shared++
+	} else {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cl = &call{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.calls[key] = cl
+		g.stats.Calls++
+		go func() {
+			cl.val, cl.res, cl.err = fn(fctx)
+			cancel()
+			g.mu.Lock()
+			g.forget(key, cl)
+			g.mu.Unlock()
+			close(cl.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.val, cl.res, shared, cl.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		cl.waiters--
+		if cl.waiters == 0 {
+			cl.cancel()
+			g.forget(key, cl)
+		}
+		g.mu.Unlock()
+		return nil, nil, shared, &TransportError{Err: ctx.Err()}
+	}
+}
+
+//forget removes cl from the in-flight calls so later callers start a new request, g.mu must be held
+func (g *coalescer) forget(key string, cl *call) {
+	if g.calls[key] == cl {
+		delete(g.calls, key)
+	}
+}
+
+//CoalesceStats returns the coalescing counters for the Client
+//The zero value is returned when coalescing is not enabled
+func (c *Client) CoalesceStats() CoalesceStats {
+	if c.coalescer == nil {
+		return CoalesceStats{}
+	}
+	c.coalescer.mu.Lock()
+	defer c.coalescer.mu.Unlock()
+	return c.coalescer.stats
+}
@@ -0,0 +1,217 @@
+package newsapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalescerDo(t *testing.T) {
+	tt := []struct {
+		testName    string
+		result      []byte
+		err         error
+		expectedErr bool
+	}{
+		{"Shared result", []byte("result"), nil, false},
+		{"Shared error", nil, errors.New("upstream failure"), true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			g := &coalescer{calls: make(map[string]*call)}
+			release := make(chan struct{})
+			var runs int32
+			fn := func(context.Context) ([]byte, *Response, error) {
+				atomic.AddInt32(&runs, 1)
+				<-release
+				return v.result, nil, v.err
+			}
+
+			var wg sync.WaitGroup
+			var shared int32
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					b, _, s, err := g.do(context.Background(), "key", fn)
+					if (err != nil) != v.expectedErr {
+						t.Errorf("Unexpected error value %v", err)
+					}
+					if string(b) != string(v.result) {
+						t.Errorf("Expected '%s' got '%s'", v.result, b)
+					}
+					if s {
+						atomic.AddInt32(&shared, 1)
+					}
+				}()
+			}
+			waitForDups(t, g, "key", 4)
+			close(release)
+			wg.Wait()
+
+			if runs != 1 {
+				t.Fatalf("Expected 1 upstream call got %d", runs)
+			}
+			if shared != 4 {
+				t.Fatalf("Expected 4 shared results got %d", shared)
+			}
+			if g.stats != (CoalesceStats{Calls: 1, Shared: 4}) {
+				t.Fatalf("Unexpected stats %+v", g.stats)
+			}
+			if len(g.calls) != 0 {
+				t.Fatalf("Expected finished calls to be removed got %d", len(g.calls))
+			}
+		})
+	}
+}
+
+func TestMakeRequestCoalescing(t *testing.T) {
+	release := make(chan struct{})
+	var hits int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer testServer.Close()
+
+	c := New("TestAPIKey", WithCoalescing())
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := c.makeRequest(context.Background(), testServer.URL+"/same"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	waitForDups(t, c.coalescer, testServer.URL+"/same\x00TestAPIKey", 9)
+	close(release)
+	wg.Wait()
+
+	if hits != 1 {
+		t.Fatalf("Expected 1 upstream request got %d", hits)
+	}
+	if s := c.CoalesceStats(); s.Calls != 1 || s.Shared != 9 {
+		t.Fatalf("Unexpected stats %+v", s)
+	}
+
+	//Requests that don't overlap are sent separately
+	if _, _, err := c.makeRequest(context.Background(), testServer.URL+"/same"); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 2 {
+		t.Fatalf("Expected 2 upstream requests got %d", hits)
+	}
+}
+
+func TestCoalescingContext(t *testing.T) {
+	tt := []struct {
+		testName string
+		cancel   int //Caller whose context is cancelled, 0 started the request and 1 joined it
+	}{
+		{"Starting caller cancels", 0},
+		{"Waiting caller cancels", 1},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			release := make(chan struct{})
+			testServer, hits, _ := blockingServer(release)
+			defer testServer.Close()
+			c := New("TestAPIKey", WithCoalescing())
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ctxs := []context.Context{context.Background(), context.Background()}
+			ctxs[v.cancel] = ctx
+			errs := make([]chan error, 2)
+			for i := range ctxs {
+				errs[i] = make(chan error, 1)
+				go func(i int) {
+					_, _, err := c.makeRequest(ctxs[i], testServer.URL+"/same")
+					errs[i] <- err
+				}(i)
+				waitForDups(t, c.coalescer, testServer.URL+"/same\x00TestAPIKey", i)
+			}
+
+			cancel()
+			if err := <-errs[v.cancel]; !errors.Is(err, context.Canceled) {
+				t.Fatalf("Expected the cancelled caller to stop waiting with context.Canceled got %v", err)
+			}
+			close(release)
+			if err := <-errs[1-v.cancel]; err != nil {
+				t.Fatalf("Expected the other caller to get the shared reply got %v", err)
+			}
+			if n := atomic.LoadInt32(hits); n != 1 {
+				t.Fatalf("Expected 1 upstream request got %d", n)
+			}
+		})
+	}
+}
+
+func TestCoalescingCancelledWhenAbandoned(t *testing.T) {
+	testServer, _, cancelled := blockingServer(make(chan struct{}))
+	defer testServer.Close()
+	c := New("TestAPIKey", WithCoalescing())
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := c.makeRequest(ctx, testServer.URL+"/same")
+		errc <- err
+	}()
+	waitForDups(t, c.coalescer, testServer.URL+"/same\x00TestAPIKey", 0)
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled got %v", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the shared request to be cancelled once no caller was waiting")
+	}
+}
+
+//blockingServer replies once release is closed, counting requests in hits and signalling cancelled when a request is cancelled first
+func blockingServer(release chan struct{}) (*httptest.Server, *int32, chan struct{}) {
+	hits := new(int32)
+	cancelled := make(chan struct{}, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		select {
+		case <-release:
+			w.Write([]byte(`{"status":"ok"}`))
+		case <-r.Context().Done():
+			cancelled <- struct{}{}
+		}
+	}))
+	return s, hits, cancelled
+}
+
+func TestCoalesceStatsDisabled(t *testing.T) {
+	c := New("TestAPIKey")
+	if s := c.CoalesceStats(); s != (CoalesceStats{}) {
+		t.Fatalf("Expected zero stats got %+v", s)
+	}
+}
+
+//waitForDups blocks until n callers are waiting on the in-flight call for key
+func waitForDups(t *testing.T, g *coalescer, key string, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		cl, ok := g.calls[key]
+		done := ok && cl.dups == n
+		g.mu.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d coalesced callers", n)
+}
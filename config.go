@@ -0,0 +1,300 @@
+package newsapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//Environment variables read by LoadProfile, each overrides the matching setting of the profile
+const (
+	EnvConfig   = "NEWSAPI_CONFIG"    //Config file to read instead of the default
+	EnvProfile  = "NEWSAPI_PROFILE"   //Profile to use when none is named
+	EnvKey      = "NEWSAPI_KEY"       //API key
+	EnvBaseURL  = "NEWSAPI_BASE_URL"  //Base URL of a NewsAPI compatible server
+	EnvPlan     = "NEWSAPI_PLAN"      //Plan name, developer or business
+	EnvCacheDir = "NEWSAPI_CACHE_DIR" //Directory tools cache files in
+)
+
+//defaultParameters maps the parameters a profile can set defaults for to the environment variable overriding each
+var defaultParameters = map[string]string{
+	"country":  "NEWSAPI_COUNTRY",
+	"category": "NEWSAPI_CATEGORY",
+	"language": "NEWSAPI_LANGUAGE",
+	"sortBy":   "NEWSAPI_SORT_BY",
+}
+
+//planNames maps the plan names a profile accepts to NewsAPI plans
+var planNames = map[string]Plan{
+	"developer": DeveloperPlan,
+	"business":  BusinessPlan,
+}
+
+//PlanByName returns the plan called name, developer or business, ignoring case
+func PlanByName(name string) (Plan, bool) {
+	p, ok := planNames[strings.ToLower(name)]
+	return p, ok
+}
+
+//PlanNames returns the names PlanByName accepts in alphabetical order
+func PlanNames() []string {
+	names := make([]string, 0, len(planNames))
+	for n := range planNames {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//Config is the contents of the newsapi config file, the settings at the top level are shared by every profile
+//
+//	key: abc123
+//	profile: work
+//	profiles:
+//	  work:
+//	    key: def456
+//	    plan: business
+//	    defaults:
+//	      country: gb
+type Config struct {
+	Shared   Profile            `yaml:",inline"`
+	Default  string             `yaml:"profile"` //Profile used when none is named
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+//Profile holds the settings for one NewsAPI account or server, empty settings aren't applied
+type Profile struct {
+	Key      Secret            `yaml:"key"`
+	BaseURL  string            `yaml:"baseURL"`
+	Plan     string            `yaml:"plan"`     //developer or business
+	CacheDir string            `yaml:"cacheDir"` //Where tools such as the CLI keep cached files, the Client doesn't use it
+	Defaults map[string]string `yaml:"defaults"` //Parameters sent when a request doesn't set them: country, category, language or sortBy
+}
+
+//DefaultConfigPath returns the config file named by NEWSAPI_CONFIG, or config.yaml in DefaultConfigDir
+//An empty string is returned when there's no home directory, getenv is os.Getenv when nil
+func DefaultConfigPath(getenv func(string) string) string {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	if p := getenv(EnvConfig); p != "" {
+		return p
+	}
+	dir := DefaultConfigDir(getenv)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
+//DefaultConfigDir returns $XDG_CONFIG_HOME/newsapi or ~/.config/newsapi
+//An empty string is returned when there's no home directory, getenv is os.Getenv when nil
+func DefaultConfigDir(getenv func(string) string) string {
+	return userDir(getenv, "XDG_CONFIG_HOME", ".config")
+}
+
+//DefaultCacheDir returns $XDG_CACHE_HOME/newsapi or ~/.cache/newsapi, for tools to use when a profile has no cacheDir
+//An empty string is returned when there's no home directory, getenv is os.Getenv when nil
+func DefaultCacheDir(getenv func(string) string) string {
+	return userDir(getenv, "XDG_CACHE_HOME", ".cache")
+}
+
+//userDir returns the newsapi directory in the XDG base directory named by env, or in fallback in the home directory
+func userDir(getenv func(string) string, env, fallback string) string {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	dir := getenv(env)
+	if dir == "" {
+		home := getenv("HOME")
+		if home == "" {
+			return ""
+		}
+		dir = filepath.Join(home, fallback)
+	}
+	return filepath.Join(dir, "newsapi")
+}
+
+//LoadConfig reads the config file at path
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("Invalid config file %v: %v", path, err)
+	}
+	return &c, nil
+}
+
+//LoadProfile reads the profile called name from the config file at path and applies the NEWSAPI_* environment variables to it
+//An empty path reads the default config file, which doesn't have to exist
+//An empty name uses NEWSAPI_PROFILE, then the config file's default profile, then only the shared settings
+func LoadProfile(path, name string, getenv func(string) string) (Profile, error) {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	c := &Config{}
+	explicit := path != ""
+	if !explicit {
+		path = DefaultConfigPath(getenv)
+	}
+	if path != "" {
+		loaded, err := LoadConfig(path)
+		switch {
+		case err == nil:
+			c = loaded
+		case !os.IsNotExist(err) || explicit || getenv(EnvConfig) != "":
+			return Profile{}, err
+		}
+	}
+	return c.Profile(name, getenv)
+}
+
+//Profile returns the profile called name merged over the shared settings with the NEWSAPI_* environment variables applied
+//See LoadProfile for how an empty name is resolved
+func (c *Config) Profile(name string, getenv func(string) string) (Profile, error) {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	if name == "" {
+		name = getenv(EnvProfile)
+	}
+	if name == "" {
+		name = c.Default
+	}
+	p := c.Shared.merge(Profile{})
+	if name != "" {
+		named, ok := c.Profiles[name]
+		if !ok {
+			return Profile{}, fmt.Errorf("Unknown profile %v, expected one of %v", name, strings.Join(c.profileNames(), ", "))
+		}
+		p = p.merge(named)
+	}
+	env := Profile{
+		Key:      Secret(getenv(EnvKey)),
+		BaseURL:  getenv(EnvBaseURL),
+		Plan:     getenv(EnvPlan),
+		CacheDir: getenv(EnvCacheDir),
+		Defaults: make(map[string]string),
+	}
+	for param, k := range defaultParameters {
+		if v := getenv(k); v != "" {
+			env.Defaults[param] = v
+		}
+	}
+	p = p.merge(env)
+	if strings.HasPrefix(p.CacheDir, "~/") && getenv("HOME") != "" {
+		p.CacheDir = filepath.Join(getenv("HOME"), p.CacheDir[2:])
+	}
+	return p, p.validate()
+}
+
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for n := range c.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//merge returns p with the non empty settings of o replacing its own
+func (p Profile) merge(o Profile) Profile {
+	if o.Key != "" {
+		p.Key = o.Key
+	}
+	if o.BaseURL != "" {
+		p.BaseURL = o.BaseURL
+	}
+	if o.Plan != "" {
+		p.Plan = o.Plan
+	}
+	if o.CacheDir != "" {
+		p.CacheDir = o.CacheDir
+	}
+	d := make(map[string]string, len(p.Defaults)+len(o.Defaults))
+	for k, v := range p.Defaults {
+		d[k] = v
+	}
+	for k, v := range o.Defaults {
+		d[k] = v
+	}
+	p.Defaults = d
+	return p
+}
+
+func (p Profile) validate() error {
+	if _, ok := PlanByName(p.Plan); p.Plan != "" && !ok {
+		return fmt.Errorf("Unknown plan %v, expected developer or business", p.Plan)
+	}
+	for k := range p.Defaults {
+		if _, ok := defaultParameters[k]; !ok {
+			return fmt.Errorf("Unsupported default %v, expected country, category, language or sortBy", k)
+		}
+	}
+	return nil
+}
+
+//WithProfile configures the Client from the profile called name in the default config file and the environment, see LoadProfile
+//The profile's key is only used when New was given an empty key, an error loading the profile is returned by every request
+func WithProfile(name string) option {
+	return func(c *Client) {
+		p, err := LoadProfile("", name, nil)
+		if err != nil {
+			c.configErr = err
+			return
+		}
+		WithProfileConfig(p)(c)
+	}
+}
+
+//WithProfileConfig configures the Client from a loaded Profile, the key is only used when New was given an empty key
+func WithProfileConfig(p Profile) option {
+	return func(c *Client) {
+		if c.APIKey == "" {
+			c.APIKey = p.Key
+		}
+		if p.BaseURL != "" {
+			WithBaseURL(p.BaseURL)(c)
+		}
+		if plan, ok := PlanByName(p.Plan); ok {
+			WithPlan(plan)(c)
+		}
+		if len(p.Defaults) > 0 {
+			c.defaults = p.Defaults
+		}
+	}
+}
+
+//withDefaults returns a copy of p with the Client's default parameters added where e accepts them and p doesn't set them
+//Top headlines can't mix sources with country or category, so those defaults aren't added when p has sources
+func (c *Client) withDefaults(e Endpoint, p parameters, ap *allowedParameters) parameters {
+	if len(c.defaults) == 0 {
+		return p
+	}
+	out := make(parameters, len(p)+len(c.defaults))
+	for k, v := range p {
+		out[k] = v
+	}
+	_, sources := p["sources"]
+	for k, v := range c.defaults {
+		if _, ok := (*ap)[k]; !ok {
+			continue
+		}
+		if _, ok := p[k]; ok {
+			continue
+		}
+		if e == EndpointTopHeadlines && sources && (k == "country" || k == "category") {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
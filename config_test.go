@@ -0,0 +1,187 @@
+package newsapi
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testConfig = `key: shared
+baseURL: https://shared.example.com/v2
+defaults:
+  language: en
+profile: work
+profiles:
+  work:
+    key: work
+    plan: business
+    cacheDir: ~/cache/work
+    defaults:
+      country: gb
+  home:
+    baseURL: https://home.example.com/v2
+    plan: Developer
+  bad-plan:
+    plan: enterprise
+  bad-default:
+    defaults:
+      q: go
+`
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	ioutil.WriteFile(path, []byte(testConfig), 0600)
+	invalid := filepath.Join(dir, "invalid.yaml")
+	ioutil.WriteFile(invalid, []byte("profiles: ["), 0600)
+	xdg := filepath.Join(dir, "xdg")
+	os.MkdirAll(filepath.Join(xdg, "newsapi"), 0700)
+	ioutil.WriteFile(filepath.Join(xdg, "newsapi", "config.yaml"), []byte("key: from-xdg\n"), 0600)
+
+	tt := []struct {
+		testName string
+		path     string
+		name     string
+		vars     map[string]string
+		expected Profile
+		err      string
+	}{
+		{"Default profile", path, "", map[string]string{"HOME": "/home/me"}, Profile{Key: "work", BaseURL: "https://shared.example.com/v2", Plan: "business", CacheDir: "/home/me/cache/work", Defaults: map[string]string{"language": "en", "country": "gb"}}, ""},
+		{"Named profile", path, "home", nil, Profile{Key: "shared", BaseURL: "https://home.example.com/v2", Plan: "Developer", Defaults: map[string]string{"language": "en"}}, ""},
+		{"Profile from environment", path, "", map[string]string{"NEWSAPI_PROFILE": "home"}, Profile{Key: "shared", BaseURL: "https://home.example.com/v2", Plan: "Developer", Defaults: map[string]string{"language": "en"}}, ""},
+		{"Environment overrides", path, "home", map[string]string{"NEWSAPI_KEY": "env", "NEWSAPI_BASE_URL": "http://localhost:8080", "NEWSAPI_PLAN": "business", "NEWSAPI_CACHE_DIR": "/tmp/cache", "NEWSAPI_LANGUAGE": "de", "NEWSAPI_SORT_BY": "popularity"}, Profile{Key: "env", BaseURL: "http://localhost:8080", Plan: "business", CacheDir: "/tmp/cache", Defaults: map[string]string{"language": "de", "sortBy": "popularity"}}, ""},
+		{"Environment only", "", "", map[string]string{"NEWSAPI_KEY": "env", "NEWSAPI_COUNTRY": "us"}, Profile{Key: "env", Defaults: map[string]string{"country": "us"}}, ""},
+		{"XDG config home", "", "", map[string]string{"XDG_CONFIG_HOME": xdg}, Profile{Key: "from-xdg", Defaults: map[string]string{}}, ""},
+		{"Config from environment", "", "home", map[string]string{"NEWSAPI_CONFIG": path}, Profile{Key: "shared", BaseURL: "https://home.example.com/v2", Plan: "Developer", Defaults: map[string]string{"language": "en"}}, ""},
+		{"Missing default", "", "", map[string]string{"HOME": dir}, Profile{Defaults: map[string]string{}}, ""},
+		{"Missing explicit", filepath.Join(dir, "missing.yaml"), "", nil, Profile{}, "no such file"},
+		{"Missing from environment", "", "", map[string]string{"NEWSAPI_CONFIG": filepath.Join(dir, "missing.yaml")}, Profile{}, "no such file"},
+		{"Invalid", invalid, "", nil, Profile{}, "Invalid config file"},
+		{"Unknown profile", path, "other", nil, Profile{}, "Unknown profile other, expected one of bad-default, bad-plan, home, work"},
+		{"Unknown plan", path, "bad-plan", nil, Profile{}, "Unknown plan enterprise"},
+		{"Unsupported default", path, "bad-default", nil, Profile{}, "Unsupported default q"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			p, err := LoadProfile(v.path, v.name, func(k string) string { return v.vars[k] })
+			if v.err != "" {
+				if err == nil || !strings.Contains(err.Error(), v.err) {
+					t.Fatalf("Expected error '%v' got %v", v.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(p, v.expected) {
+				t.Fatalf("Expected %+v got %+v", v.expected, p)
+			}
+		})
+	}
+}
+
+func TestWithProfileConfig(t *testing.T) {
+	p := Profile{Key: "profile", BaseURL: "http://localhost:8080/v2/", Plan: "developer", Defaults: map[string]string{"country": "gb", "category": "science", "language": "en", "sortBy": "publishedAt"}}
+	tt := []struct {
+		testName string
+		key      string
+		request  Request
+		expected string
+		header   string
+	}{
+		{"Top headlines", "", Request{EndpointTopHeadlines, map[string]interface{}{"q": "test"}}, "http://localhost:8080/v2/top-headlines?category=science&country=gb&q=test", "profile"},
+		{"Parameter set", "", Request{EndpointTopHeadlines, map[string]interface{}{"country": "us"}}, "http://localhost:8080/v2/top-headlines?category=science&country=us", "profile"},
+		{"Top headlines sources", "", Request{EndpointTopHeadlines, map[string]interface{}{"sources": []string{"bbc-news"}}}, "http://localhost:8080/v2/top-headlines?sources=bbc-news", "profile"},
+		{"Everything", "", Request{EndpointEverything, map[string]interface{}{"q": "test"}}, "http://localhost:8080/v2/everything?language=en&q=test&sortBy=publishedAt", "profile"},
+		{"Sources", "", Request{EndpointSources, nil}, "http://localhost:8080/v2/sources?category=science&country=gb&language=en", "profile"},
+		{"Key given to New", "abc", Request{EndpointSources, map[string]interface{}{"language": "de"}}, "http://localhost:8080/v2/sources?category=science&country=gb&language=de", "abc"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New(v.key, WithProfileConfig(p))
+			req, err := c.BuildRequest(context.Background(), v.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.URL.String() != v.expected || req.Header.Get("X-Api-Key") != v.header {
+				t.Fatalf("Expected %v with key %v got %v with key %v", v.expected, v.header, req.URL, req.Header.Get("X-Api-Key"))
+			}
+		})
+	}
+
+	c := New("", WithProfileConfig(p))
+	if _, err := c.BuildRequest(context.Background(), Request{EndpointEverything, map[string]interface{}{"q": "test", "page": 10}}); err == nil {
+		t.Fatal("Expected the profile's plan limits to be enforced")
+	}
+}
+
+func TestWithProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	ioutil.WriteFile(path, []byte(testConfig), 0600)
+	t.Setenv("NEWSAPI_CONFIG", path)
+	t.Setenv("NEWSAPI_PROFILE", "")
+	t.Setenv("NEWSAPI_KEY", "")
+
+	c := New("", WithProfile("home"))
+	if c.APIKey != "shared" || c.APIUrl != "https://home.example.com/v2" {
+		t.Fatalf("Expected key shared and URL https://home.example.com/v2 got %v and %v", string(c.APIKey), c.APIUrl)
+	}
+
+	c = New("abc", WithProfile("other"))
+	if _, err := c.GetSources(nil); err == nil || !strings.Contains(err.Error(), "Unknown profile other") {
+		t.Fatalf("Expected unknown profile error got %v", err)
+	}
+}
+
+func TestPlanByName(t *testing.T) {
+	tt := []struct {
+		testName string
+		name     string
+		expected Plan
+		ok       bool
+	}{
+		{"Developer", "developer", DeveloperPlan, true},
+		{"Mixed case", "Business", BusinessPlan, true},
+		{"Unknown", "enterprise", Plan{}, false},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			p, ok := PlanByName(v.name)
+			if ok != v.ok || !reflect.DeepEqual(p, v.expected) {
+				t.Fatalf("Expected %+v %v got %+v %v", v.expected, v.ok, p, ok)
+			}
+		})
+	}
+	if n := strings.Join(PlanNames(), ","); n != "business,developer" {
+		t.Fatalf("Expected plan names business,developer got %v", n)
+	}
+}
+
+func TestDefaultDirs(t *testing.T) {
+	tt := []struct {
+		testName string
+		vars     map[string]string
+		config   string
+		cache    string
+	}{
+		{"Home", map[string]string{"HOME": "/home/me"}, "/home/me/.config/newsapi", "/home/me/.cache/newsapi"},
+		{"XDG", map[string]string{"HOME": "/home/me", "XDG_CONFIG_HOME": "/xdg/config", "XDG_CACHE_HOME": "/xdg/cache"}, "/xdg/config/newsapi", "/xdg/cache/newsapi"},
+		{"No home", nil, "", ""},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			getenv := func(k string) string { return v.vars[k] }
+			if d := DefaultConfigDir(getenv); d != v.config {
+				t.Fatalf("Expected config dir '%v' got '%v'", v.config, d)
+			}
+			if d := DefaultCacheDir(getenv); d != v.cache {
+				t.Fatalf("Expected cache dir '%v' got '%v'", v.cache, d)
+			}
+		})
+	}
+}
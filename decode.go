@@ -0,0 +1,65 @@
+package newsapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+//maxExcerpt is the most of a body included in a DecodeError
+const maxExcerpt = 256
+
+//DecodeError is returned when a NewsAPI reply can't be decoded into the result type
+type DecodeError struct {
+	Err     error
+	Excerpt string //Part of the body around where decoding failed
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("Failed to decode NewsAPI response: %v near '%v'", e.Err, e.Excerpt)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+//WithStrictDecoding makes the Client return a DecodeError when a reply contains fields the result types don't know about
+//This is useful for spotting changes to the NewsAPI schema early
+func WithStrictDecoding() option {
+	return func(c *Client) {
+		c.strict = true
+	}
+}
+
+//decode unmarshals the body b into v, returning a DecodeError on failure
+func (c *Client) decode(b []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	if c.strict {
+		d.DisallowUnknownFields()
+	}
+	err := d.Decode(v)
+	if err == nil {
+		return nil
+	}
+	offset := d.InputOffset()
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	}
+	return &DecodeError{Err: err, Excerpt: excerpt(b, offset)}
+}
+
+//excerpt returns up to maxExcerpt bytes of b ending around offset
+func excerpt(b []byte, offset int64) string {
+	end := int(offset) + maxExcerpt/4
+	if end > len(b) {
+		end = len(b)
+	}
+	start := end - maxExcerpt
+	if start < 0 {
+		start = 0
+	}
+	return string(b[start:end])
+}
@@ -0,0 +1,108 @@
+package newsapi
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tt := []struct {
+		testName        string
+		strict          bool
+		body            string
+		expectedError   bool
+		expectedExcerpt string
+	}{
+		{"Valid", false, `{"status":"ok","totalResults":1,"articles":[{"title":"Title"}]}`, false, ""},
+		{"Unknown field lenient", false, `{"status":"ok","newField":true}`, false, ""},
+		{"Unknown field strict", true, `{"status":"ok","newField":true}`, true, `"newField"`},
+		{"Syntax error", false, `{"status":"ok",totalResults}`, true, "totalResults"},
+		{"Wrong type", false, `{"status":"ok","totalResults":"many"}`, true, `"many"`},
+		{"Truncated body", false, `{"status":"ok","articles":[`, true, "articles"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New("TestAPIKey")
+			if v.strict {
+				WithStrictDecoding()(c)
+			}
+			var o ArticleResults
+			err := c.decode([]byte(v.body), &o)
+			if err == nil {
+				if v.expectedError {
+					t.Fatal("Expected error got nil")
+				}
+				return
+			}
+			if !v.expectedError {
+				t.Fatalf("Unexpected error - %v", err)
+			}
+			var decErr *DecodeError
+			if !errors.As(err, &decErr) {
+				t.Fatalf("Expected DecodeError got %T", err)
+			}
+			if !strings.Contains(decErr.Excerpt, v.expectedExcerpt) {
+				t.Fatalf("Expected excerpt to contain '%v' got '%v'", v.expectedExcerpt, decErr.Excerpt)
+			}
+		})
+	}
+}
+
+func TestExcerptLength(t *testing.T) {
+	b := []byte(strings.Repeat("a", 1000))
+	if e := excerpt(b, 500); len(e) != maxExcerpt {
+		t.Fatalf("Expected excerpt of %d bytes got %d", maxExcerpt, len(e))
+	}
+	if e := excerpt([]byte("short"), 2); e != "short" {
+		t.Fatalf("Expected 'short' got '%v'", e)
+	}
+}
+
+func TestStrictDecodingFixtures(t *testing.T) {
+	tt := []struct {
+		testName string
+		file     string
+		get      func(c *Client) error
+	}{
+		{"Top headlines", "testdata/topheadlines_sucess.json", func(c *Client) error { _, err := c.GetTopHeadlines(parameters{}); return err }},
+		{"Everything", "testdata/everything_sucess.json", func(c *Client) error { _, err := c.GetEverything(parameters{}); return err }},
+		{"Sources", "testdata/sources_sucess.json", func(c *Client) error { _, err := c.GetSources(parameters{}); return err }},
+	}
+
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			d, err := ioutil.ReadFile(v.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testServer := fakeServer(d, nil)
+			defer testServer.Close()
+
+			c := New("TestAPIKey", WithStrictDecoding())
+			c.APIUrl = testServer.URL
+			if err := v.get(c); err != nil {
+				t.Fatalf("Unexpected error - %v", err)
+			}
+		})
+	}
+}
+
+func TestMalformedResponseReturnsError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok","articles":[{"title":`))
+	}))
+	defer testServer.Close()
+
+	c := New("TestAPIKey")
+	c.APIUrl = testServer.URL
+	_, err := c.GetTopHeadlines(parameters{})
+	var decErr *DecodeError
+	if !errors.As(err, &decErr) {
+		t.Fatalf("Expected DecodeError got %v", err)
+	}
+}
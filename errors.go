@@ -0,0 +1,76 @@
+package newsapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//maxDrain is the most of an unread body that is discarded so a connection can be reused
+const maxDrain = 64 << 10
+
+type errorResponse struct {
+	Status  string `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+//APIError is returned when NewsAPI replies with an error payload
+//https://newsapi.org/docs/errors
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RetryAfter time.Duration //Parsed from the Retry-After header, zero when not sent
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+//UpstreamError is returned when an error reply isn't a NewsAPI error payload
+//This usually means a proxy or CDN in front of NewsAPI replied, for example with an HTML 502 page
+type UpstreamError struct {
+	StatusCode  int
+	ContentType string
+	Excerpt     string //Start of the reply body
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("Unexpected %d %v response from upstream with content type '%v'", e.StatusCode, http.StatusText(e.StatusCode), e.ContentType)
+}
+
+//TransportError is returned when a request couldn't be sent or its reply couldn't be read
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("Request to NewsAPI failed: %v", e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+//errorFromResponse builds the error for a non 200 reply with body b
+func errorFromResponse(res *http.Response, b []byte) error {
+	var errResp errorResponse
+	if json.Unmarshal(b, &errResp) == nil && errResp.Status == "error" {
+		apiErr := &APIError{StatusCode: res.StatusCode, Code: errResp.Code, Message: errResp.Message}
+		if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(secs) * time.Second
+		}
+		return apiErr
+	}
+	if len(b) > maxExcerpt {
+		b = b[:maxExcerpt]
+	}
+	return &UpstreamError{
+		StatusCode:  res.StatusCode,
+		ContentType: res.Header.Get("Content-Type"),
+		Excerpt:     string(b),
+	}
+}
@@ -0,0 +1,125 @@
+package newsapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestErrors(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html><body><h1>502 Bad Gateway</h1></body></html>"))
+		case "/empty":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusGatewayTimeout)
+			w.Write([]byte(`{"message":"Endpoint request timed out"}`))
+		case "/newsapi":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error","code":"apiKeyInvalid","message":"Your API key is invalid or incorrect."}`))
+		}
+	}))
+	defer testServer.Close()
+
+	tt := []struct {
+		testName    string
+		url         string
+		check       func(err error) bool
+		description string
+	}{
+		{"HTML error page", testServer.URL + "/html", func(err error) bool {
+			e, ok := err.(*UpstreamError)
+			return ok && e.StatusCode == http.StatusBadGateway && e.ContentType == "text/html" && e.Excerpt == "<html><body><h1>502 Bad Gateway</h1></body></html>"
+		}, "UpstreamError with HTML content"},
+		{"Empty error body", testServer.URL + "/empty", func(err error) bool {
+			e, ok := err.(*UpstreamError)
+			return ok && e.StatusCode == http.StatusServiceUnavailable
+		}, "UpstreamError with 503 status"},
+		{"Non NewsAPI JSON", testServer.URL + "/json", func(err error) bool {
+			_, ok := err.(*UpstreamError)
+			return ok
+		}, "UpstreamError"},
+		{"NewsAPI error", testServer.URL + "/newsapi", func(err error) bool {
+			e, ok := err.(*APIError)
+			return ok && e.Code == "apiKeyInvalid" && e.StatusCode == http.StatusUnauthorized
+		}, "APIError with apiKeyInvalid code"},
+		{"Transport failure", "http://127.0.0.1:1", func(err error) bool {
+			var e *TransportError
+			var opErr *net.OpError
+			return errors.As(err, &e) && errors.As(err, &opErr)
+		}, "TransportError wrapping the dial error"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New("TestAPIKey")
+			c.HTTPClient.Timeout = time.Second * 2
+			_, _, err := c.makeRequest(context.Background(), v.url)
+			if !v.check(err) {
+				t.Fatalf("Expected %v got %T '%v'", v.description, err, err)
+			}
+		})
+	}
+}
+
+func TestUpstreamErrorExcerptLimit(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write(make([]byte, maxExcerpt*2))
+	}))
+	defer testServer.Close()
+
+	c := New("TestAPIKey")
+	_, _, err := c.makeRequest(context.Background(), testServer.URL)
+	e, ok := err.(*UpstreamError)
+	if !ok {
+		t.Fatalf("Expected UpstreamError got %v", err)
+	}
+	if len(e.Excerpt) != maxExcerpt {
+		t.Fatalf("Expected excerpt of %d bytes got %d", maxExcerpt, len(e.Excerpt))
+	}
+}
+
+func TestConnectionReuse(t *testing.T) {
+	var conns int32
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("reply") {
+		case "error":
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>Bad Gateway</html>" + strings.Repeat(" ", 16<<10)))
+		default:
+			//Trailing whitespace is left unread by the streaming decoder
+			w.Write([]byte(`{"status":"ok","totalResults":1,"articles":[{"title":"a"}]}` + strings.Repeat("\n", 48<<10)))
+		}
+	}))
+	testServer.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	testServer.Start()
+	defer testServer.Close()
+
+	c := New("TestAPIKey")
+	c.APIUrl = testServer.URL
+	for i := 0; i < 3; i++ {
+		c.makeRequest(context.Background(), testServer.URL+"?reply=error")
+		c.makeRequest(context.Background(), testServer.URL)
+		c.StreamEverything(parameters{}, func(Article) error { return nil })
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Fatalf("Expected 1 connection to be reused got %d", n)
+	}
+}
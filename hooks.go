@@ -0,0 +1,84 @@
+package newsapi
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+//Endpoint identifies a NewsAPI endpoint
+type Endpoint string
+
+const (
+	EndpointTopHeadlines Endpoint = "top-headlines"
+	EndpointEverything   Endpoint = "everything"
+	EndpointSources      Endpoint = "sources"
+)
+
+//Event describes a finished call to a NewsAPI endpoint
+type Event struct {
+	Context  context.Context //Context the call was made with, spans and log records can use it as their parent
+	Endpoint Endpoint
+	Query    url.Values //Parameters sent with the request, any API key is redacted
+	Start    time.Time
+	Duration time.Duration
+	Response *Response //nil when no reply was received
+	Results  int       //Number of articles or sources returned
+	Err      error
+}
+
+//Code returns the NewsAPI error code for the call, or an empty string when NewsAPI didn't return an error
+func (e Event) Code() string {
+	if apiErr, ok := e.Err.(*APIError); ok {
+		return apiErr.Code
+	}
+	return ""
+}
+
+//Hook is called with an Event after each call made by a Client
+//Hooks are called synchronously so they should return quickly
+type Hook func(Event)
+
+//WithHook adds a Hook to the Client
+func WithHook(h Hook) option {
+	return func(c *Client) {
+		c.AddHook(h)
+	}
+}
+
+//AddHook adds a Hook to an existing Client
+//It isn't safe to call AddHook while the Client is in use
+func (c *Client) AddHook(h Hook) {
+	c.hooks = append(c.hooks, h)
+}
+
+//observe passes a finished call to rawURL made with ctx to the Client's hooks, dry runs aren't passed on
+func (c *Client) observe(ctx context.Context, e Event, rawURL string) {
+	if _, ok := e.Err.(*DryRunError); ok || len(c.hooks) == 0 {
+		return
+	}
+	e.Context = ctx
+	e.Duration = time.Since(e.Start)
+	e.Query = redactQuery(rawURL)
+	for _, h := range c.hooks {
+		h(e)
+	}
+}
+
+//redactedKeyParams are query parameters that can hold an API key
+var redactedKeyParams = []string{"apiKey", "apikey"}
+
+//redactQuery returns the query parameters of rawURL with any API key replaced
+func redactQuery(rawURL string) url.Values {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return url.Values{}
+	}
+	q := u.Query()
+	for _, k := range redactedKeyParams {
+		if q.Get(k) != "" {
+			q.Set(k, redacted)
+		}
+	}
+	return q
+}
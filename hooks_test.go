@@ -0,0 +1,83 @@
+package newsapi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	testServer := keyServer()
+	defer testServer.Close()
+
+	var events []Event
+	c := New("invalid-key", WithHook(func(e Event) { events = append(events, e) }))
+	c.AddHook(func(e Event) { events = append(events, e) })
+	c.APIUrl = testServer.URL
+
+	c.GetTopHeadlines(parameters{"country": "gb", "page": 2})
+	if len(events) != 2 {
+		t.Fatalf("Expected both hooks to be called got %d events", len(events))
+	}
+	e := events[0]
+	if e.Endpoint != EndpointTopHeadlines {
+		t.Fatalf("Expected endpoint %v got %v", EndpointTopHeadlines, e.Endpoint)
+	}
+	if e.Query.Get("country") != "gb" || e.Query.Get("page") != "2" {
+		t.Fatalf("Unexpected query %v", e.Query)
+	}
+	if e.Code() != "apiKeyInvalid" || e.Response == nil || e.Response.StatusCode != 401 {
+		t.Fatalf("Unexpected event %+v", e)
+	}
+	if e.Duration <= 0 || e.Start.IsZero() || time.Since(e.Start) < e.Duration {
+		t.Fatalf("Unexpected timing %v %v", e.Start, e.Duration)
+	}
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "caller")
+	c.GetSourcesContext(ctx, nil)
+	if e := events[len(events)-1]; e.Context == nil || e.Context.Value(ctxKey{}) != "caller" {
+		t.Fatal("Expected the event to carry the caller's context")
+	}
+}
+
+func TestEventCode(t *testing.T) {
+	tt := []struct {
+		testName string
+		err      error
+		expected string
+	}{
+		{"No error", nil, ""},
+		{"NewsAPI error", &APIError{Code: "rateLimited"}, "rateLimited"},
+		{"Other error", errors.New("failed"), ""},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			if c := (Event{Err: v.err}).Code(); c != v.expected {
+				t.Fatalf("Expected '%v' got '%v'", v.expected, c)
+			}
+		})
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	tt := []struct {
+		testName string
+		url      string
+		expected url.Values
+	}{
+		{"No key", "https://newsapi.org/v2/everything?q=test", url.Values{"q": {"test"}}},
+		{"Key in query", "https://newsapi.org/v2/everything?apiKey=secret&q=test", url.Values{"apiKey": {"REDACTED"}, "q": {"test"}}},
+		{"Lower case key", "https://newsapi.org/v2/everything?apikey=secret", url.Values{"apikey": {"REDACTED"}}},
+		{"Invalid URL", "://", url.Values{}},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			if r := redactQuery(v.url); r.Encode() != v.expected.Encode() {
+				t.Fatalf("Expected '%v' got '%v'", v.expected, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package newsapi
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+//KeyStrategy decides which key a KeyPool hands out next
+type KeyStrategy int
+
+const (
+	//RoundRobin cycles through the usable keys in the order they were added
+	RoundRobin KeyStrategy = iota
+	//MostRemaining picks the usable key with the largest remaining daily budget
+	MostRemaining
+)
+
+//ErrNoKeysAvailable is returned when every key in a KeyPool is exhausted or disabled
+var ErrNoKeysAvailable = errors.New("No API keys available in key pool")
+
+//budgetPeriod is the window a key's budget applies to
+const budgetPeriod = 24 * time.Hour
+
+//defaultCooldown is used when a KeyPool's Cooldown is zero
+const defaultCooldown = time.Hour
+
+//KeyPool holds several NewsAPI keys and fails over between them
+//Keys that report apiKeyExhausted or rateLimited are skipped until their reset time
+//Keys that report apiKeyDisabled, apiKeyInvalid or apiKeyMissing are skipped permanently
+type KeyPool struct {
+	Strategy KeyStrategy
+	Cooldown time.Duration //How long a key is skipped when NewsAPI doesn't send a Retry-After header, an hour when zero
+
+	mu   sync.Mutex
+	keys []*poolKey
+	next int
+	now  func() time.Time
+}
+
+type poolKey struct {
+	key            Secret
+	budget         int //Requests allowed per budgetPeriod, zero means unlimited
+	used           int
+	periodStart    time.Time
+	requests       int64
+	failures       int64
+	exhaustedUntil time.Time
+	disabled       bool
+	reason         string
+}
+
+//KeyStats contains the usage of a single key in a KeyPool
+type KeyStats struct {
+	Key            string //Redacted form of the key
+	Requests       int64
+	Failures       int64
+	Budget         int
+	Remaining      int //Remaining budget for the current period, -1 when the key has no budget
+	ExhaustedUntil time.Time
+	Disabled       bool
+	Reason         string //NewsAPI error code that caused the key to be skipped
+}
+
+//NewKeyPool creates a KeyPool containing keys with no daily budget
+func NewKeyPool(strategy KeyStrategy, keys ...string) *KeyPool {
+	p := &KeyPool{
+		Strategy: strategy,
+		Cooldown: defaultCooldown,
+		now:      time.Now,
+	}
+	for _, k := range keys {
+		p.Add(k, 0)
+	}
+	return p
+}
+
+//Add adds a key to the pool with a daily request budget, a budget of zero means unlimited
+func (p *KeyPool) Add(key string, budget int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, &poolKey{key: Secret(key), budget: budget})
+}
+
+//WithKeyPool makes the Client take its API keys from p instead of APIKey
+func WithKeyPool(p *KeyPool) option {
+	return func(c *Client) {
+		c.keyPool = p
+	}
+}
+
+//KeyStats returns the usage of each key in the Client's key pool, nil when no pool is configured
+func (c *Client) KeyStats() []KeyStats {
+	if c.keyPool == nil {
+		return nil
+	}
+	return c.keyPool.Stats()
+}
+
+//Stats returns the usage of each key in the order they were added
+func (p *KeyPool) Stats() []KeyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.clock()
+	s := make([]KeyStats, 0, len(p.keys))
+	for _, k := range p.keys {
+		p.resetPeriod(k, now)
+		ks := KeyStats{
+			Key:       redactKey(k.key),
+			Requests:  k.requests,
+			Failures:  k.failures,
+			Budget:    k.budget,
+			Remaining: k.remaining(),
+			Disabled:  k.disabled,
+			Reason:    k.reason,
+		}
+		if k.exhaustedUntil.After(now) {
+			ks.ExhaustedUntil = k.exhaustedUntil
+		}
+		s = append(s, ks)
+	}
+	return s
+}
+
+//acquire picks the next usable key and counts it against its budget
+func (p *KeyPool) acquire() (*poolKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	picked, idx := p.pick()
+	if picked == nil {
+		return nil, ErrNoKeysAvailable
+	}
+	if p.Strategy == RoundRobin {
+		p.next = idx + 1
+	}
+	picked.used++
+	picked.requests++
+	return picked, nil
+}
+
+//peek returns the key the next call to acquire would pick without using it
+func (p *KeyPool) peek() (Secret, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	picked, _ := p.pick()
+	if picked == nil {
+		return "", ErrNoKeysAvailable
+	}
+	return picked.key, nil
+}
+
+//pick chooses a usable key according to Strategy and returns it with its index, p.mu must be held
+func (p *KeyPool) pick() (*poolKey, int) {
+	now := p.clock()
+	var picked *poolKey
+	var pickedIdx int
+	for i := range p.keys {
+		idx := (p.next + i) % len(p.keys)
+		k := p.keys[idx]
+		p.resetPeriod(k, now)
+		if !k.usable(now) {
+			continue
+		}
+		if p.Strategy == RoundRobin {
+			return k, idx
+		}
+		if picked == nil || k.remainingOrMax() > picked.remainingOrMax() {
+			picked, pickedIdx = k, idx
+		}
+	}
+	return picked, pickedIdx
+}
+
+//release records the outcome of a request made with k
+//It returns true when the request should be retried with another key
+func (p *KeyPool) release(k *poolKey, err error) bool {
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k.failures++
+	switch apiErr.Code {
+	case "apiKeyExhausted", "rateLimited":
+		wait := apiErr.RetryAfter
+		if wait == 0 {
+			wait = p.Cooldown
+		}
+		if wait <= 0 {
+			wait = defaultCooldown
+		}
+		k.exhaustedUntil = p.clock().Add(wait)
+	case "apiKeyDisabled", "apiKeyInvalid", "apiKeyMissing":
+		k.disabled = true
+	default:
+		return false
+	}
+	k.reason = apiErr.Code
+	return true
+}
+
+//clock returns the current time, falling back to time.Now for a KeyPool not created with NewKeyPool
+func (p *KeyPool) clock() time.Time {
+	if p.now == nil {
+		return time.Now()
+	}
+	return p.now()
+}
+
+//resetPeriod starts a new budget period for k once the previous one has passed
+func (p *KeyPool) resetPeriod(k *poolKey, now time.Time) {
+	if now.Sub(k.periodStart) >= budgetPeriod {
+		k.periodStart = now
+		k.used = 0
+	}
+}
+
+func (k *poolKey) usable(now time.Time) bool {
+	if k.disabled || k.exhaustedUntil.After(now) {
+		return false
+	}
+	return k.budget == 0 || k.used < k.budget
+}
+
+func (k *poolKey) remaining() int {
+	if k.budget == 0 {
+		return -1
+	}
+	return k.budget - k.used
+}
+
+//remainingOrMax treats keys without a budget as having the most remaining
+func (k *poolKey) remainingOrMax() int {
+	if k.budget == 0 {
+		return int(^uint(0) >> 1)
+	}
+	return k.budget - k.used
+}
+
+//redactKey hides all but the last four characters of an API key
+func redactKey(key Secret) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + string(key[len(key)-4:])
+}
@@ -0,0 +1,201 @@
+package newsapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+//keyServer replies based on the key sent in the X-Api-Key header
+func keyServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("X-Api-Key") {
+		case "exhausted-key":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","code":"apiKeyExhausted","message":"Your API key has no more requests available."}`))
+		case "limited-key":
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","code":"rateLimited","message":"You have been rate limited."}`))
+		case "invalid-key":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error","code":"apiKeyInvalid","message":"Your API key is invalid or incorrect."}`))
+		case "broken-key":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","code":"parametersMissing","message":"Required parameters are missing."}`))
+		default:
+			w.Write([]byte(`{"status":"ok","totalResults":0,"articles":[]}`))
+		}
+	}))
+}
+
+func TestKeyPoolFailover(t *testing.T) {
+	testServer := keyServer()
+	defer testServer.Close()
+
+	now := time.Date(2018, 7, 27, 12, 0, 0, 0, time.UTC)
+	p := NewKeyPool(RoundRobin, "exhausted-key", "limited-key", "invalid-key", "valid-key")
+	p.now = func() time.Time { return now }
+	c := New("", WithKeyPool(p))
+
+	if _, _, err := c.makeRequest(context.Background(), testServer.URL); err != nil {
+		t.Fatalf("Unexpected error - %v", err)
+	}
+
+	s := c.KeyStats()
+	tt := []struct {
+		testName       string
+		stats          KeyStats
+		exhaustedUntil time.Time
+		disabled       bool
+		reason         string
+	}{
+		{"Exhausted key uses cooldown", s[0], now.Add(time.Hour), false, "apiKeyExhausted"},
+		{"Rate limited key uses Retry-After", s[1], now.Add(30 * time.Second), false, "rateLimited"},
+		{"Invalid key is disabled", s[2], time.Time{}, true, "apiKeyInvalid"},
+		{"Valid key is used", s[3], time.Time{}, false, ""},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			if !v.stats.ExhaustedUntil.Equal(v.exhaustedUntil) {
+				t.Fatalf("Expected exhausted until %v got %v", v.exhaustedUntil, v.stats.ExhaustedUntil)
+			}
+			if v.stats.Disabled != v.disabled {
+				t.Fatalf("Expected disabled %v got %v", v.disabled, v.stats.Disabled)
+			}
+			if v.stats.Reason != v.reason {
+				t.Fatalf("Expected reason '%v' got '%v'", v.reason, v.stats.Reason)
+			}
+			if v.stats.Requests != 1 {
+				t.Fatalf("Expected 1 request got %d", v.stats.Requests)
+			}
+		})
+	}
+
+	//Only the valid key is tried until the cooldowns pass
+	if _, _, err := c.makeRequest(context.Background(), testServer.URL); err != nil {
+		t.Fatalf("Unexpected error - %v", err)
+	}
+	if r := p.Stats()[3].Requests; r != 2 {
+		t.Fatalf("Expected 2 requests on valid key got %d", r)
+	}
+	now = now.Add(2 * time.Hour)
+	p.Stats()
+	if _, _, err := c.makeRequest(context.Background(), testServer.URL); err != nil {
+		t.Fatalf("Unexpected error - %v", err)
+	}
+	if r := p.Stats()[0].Requests; r != 2 {
+		t.Fatalf("Expected exhausted key to be retried after cooldown got %d requests", r)
+	}
+}
+
+func TestKeyStatsWithoutPool(t *testing.T) {
+	if s := New("TestAPIKey").KeyStats(); s != nil {
+		t.Fatalf("Expected nil stats got %+v", s)
+	}
+}
+
+func TestKeyPoolNoKeysAvailable(t *testing.T) {
+	testServer := keyServer()
+	defer testServer.Close()
+
+	c := New("", WithKeyPool(NewKeyPool(RoundRobin, "exhausted-key", "invalid-key")))
+	_, r, err := c.makeRequest(context.Background(), testServer.URL)
+	if err != ErrNoKeysAvailable {
+		t.Fatalf("Expected '%v' got '%v'", ErrNoKeysAvailable, err)
+	}
+	if r == nil || r.Attempts != 2 {
+		t.Fatalf("Expected the last reply with 2 attempts got %+v", r)
+	}
+}
+
+func TestKeyPoolOtherErrorsNotRetried(t *testing.T) {
+	testServer := keyServer()
+	defer testServer.Close()
+
+	p := NewKeyPool(RoundRobin, "broken-key", "valid-key")
+	c := New("", WithKeyPool(p))
+	_, _, err := c.makeRequest(context.Background(), testServer.URL)
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Code != "parametersMissing" {
+		t.Fatalf("Expected parametersMissing APIError got %v", err)
+	}
+	if r := p.Stats()[1].Requests; r != 0 {
+		t.Fatalf("Expected no failover got %d requests on second key", r)
+	}
+}
+
+func TestKeyPoolStrategies(t *testing.T) {
+	tt := []struct {
+		testName string
+		strategy KeyStrategy
+		budgets  []int
+		expected []int //Index of the key picked by each acquire
+	}{
+		{"Round robin", RoundRobin, []int{0, 0, 0}, []int{0, 1, 2, 0}},
+		{"Round robin skips spent budget", RoundRobin, []int{1, 0}, []int{0, 1, 1, 1}},
+		{"Most remaining", MostRemaining, []int{2, 5, 3}, []int{1, 1, 1, 2}},
+		{"Most remaining prefers unlimited", MostRemaining, []int{5, 0}, []int{1, 1, 1, 1}},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			p := NewKeyPool(v.strategy)
+			keys := []string{"key-a", "key-b", "key-c"}
+			for i, b := range v.budgets {
+				p.Add(keys[i], b)
+			}
+			for i, e := range v.expected {
+				k, err := p.acquire()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(k.key) != keys[e] {
+					t.Fatalf("Acquire %d expected %v got %v", i, keys[e], k.key)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyPoolBudgetReset(t *testing.T) {
+	now := time.Date(2018, 7, 27, 12, 0, 0, 0, time.UTC)
+	p := NewKeyPool(RoundRobin)
+	p.now = func() time.Time { return now }
+	p.Add("budget-key", 1)
+
+	if _, err := p.acquire(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.acquire(); err != ErrNoKeysAvailable {
+		t.Fatalf("Expected '%v' got '%v'", ErrNoKeysAvailable, err)
+	}
+	now = now.Add(budgetPeriod)
+	if _, err := p.acquire(); err != nil {
+		t.Fatalf("Expected budget to reset got %v", err)
+	}
+	if s := p.Stats()[0]; s.Remaining != 0 || s.Key != "****-key" {
+		t.Fatalf("Unexpected stats %+v", s)
+	}
+}
+
+func TestKeyPoolZeroValue(t *testing.T) {
+	testServer := keyServer()
+	defer testServer.Close()
+
+	p := &KeyPool{}
+	p.Add("exhausted-key", 0)
+	p.Add("valid-key", 0)
+	c := New("", WithKeyPool(p))
+	if _, _, err := c.makeRequest(context.Background(), testServer.URL); err != nil {
+		t.Fatal(err)
+	}
+	s := p.Stats()
+	if wait := time.Until(s[0].ExhaustedUntil); wait < 59*time.Minute || wait > time.Hour {
+		t.Fatalf("Expected the exhausted key to be skipped for the default cooldown got %v", wait)
+	}
+	if s[1].Requests != 1 {
+		t.Fatalf("Expected 1 request on the second key got %d", s[1].Requests)
+	}
+}
@@ -0,0 +1,65 @@
+package newsapi
+
+import (
+	"log/slog"
+)
+
+//logger holds the slog.Logger used by a Client and the levels it logs at
+type logger struct {
+	l            *slog.Logger
+	successLevel slog.Level
+	failureLevel slog.Level
+}
+
+//WithLogger makes the Client log each call to l
+//Successful calls are logged at debug level and failed calls at warn level unless changed with WithLogLevels
+func WithLogger(l *slog.Logger) option {
+	return func(c *Client) {
+		if c.logger != nil {
+			c.logger.l = l
+			return
+		}
+		c.logger = &logger{l: l, successLevel: slog.LevelDebug, failureLevel: slog.LevelWarn}
+		c.AddHook(c.logger.log)
+	}
+}
+
+//WithLogLevels sets the levels successful and failed calls are logged at
+//It must be passed after WithLogger
+func WithLogLevels(success, failure slog.Level) option {
+	return func(c *Client) {
+		if c.logger != nil {
+			c.logger.successLevel = success
+			c.logger.failureLevel = failure
+		}
+	}
+}
+
+func (lg *logger) log(e Event) {
+	attrs := []slog.Attr{
+		slog.String("endpoint", string(e.Endpoint)),
+		slog.String("query", e.Query.Encode()),
+		slog.Duration("latency", e.Duration),
+	}
+	if r := e.Response; r != nil {
+		attrs = append(attrs,
+			slog.Int("status", r.StatusCode),
+			slog.Bool("shared", r.Shared),
+			slog.Bool("cache_hit", r.FromCache),
+			slog.Int("attempts", r.Attempts),
+		)
+	}
+	level := lg.successLevel
+	msg := "NewsAPI request succeeded"
+	if e.Err != nil {
+		level = lg.failureLevel
+		msg = "NewsAPI request failed"
+		if code := e.Code(); code != "" {
+			attrs = append(attrs, slog.String("code", code))
+		}
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	} else {
+		attrs = append(attrs, slog.Int("results", e.Results))
+	}
+	lg.l.LogAttrs(e.Context, level, msg, attrs...)
+}
@@ -0,0 +1,130 @@
+package newsapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	testServer := keyServer()
+	defer testServer.Close()
+
+	tt := []struct {
+		testName      string
+		apiKey        string
+		get           func(c *Client) error
+		levels        []slog.Level
+		expectedLevel string
+		expected      map[string]interface{}
+	}{
+		{
+			"Success",
+			"valid-key",
+			func(c *Client) error { _, err := c.GetEverything(parameters{"q": "bitcoin"}); return err },
+			nil,
+			"DEBUG",
+			map[string]interface{}{"msg": "NewsAPI request succeeded", "endpoint": "everything", "query": "q=bitcoin", "status": 200.0, "results": 0.0, "attempts": 1.0, "shared": false, "cache_hit": false},
+		},
+		{
+			"NewsAPI error",
+			"invalid-key",
+			func(c *Client) error { _, err := c.GetTopHeadlines(parameters{"country": "gb"}); return err },
+			nil,
+			"WARN",
+			map[string]interface{}{"msg": "NewsAPI request failed", "endpoint": "top-headlines", "query": "country=gb", "status": 401.0, "code": "apiKeyInvalid"},
+		},
+		{
+			"Custom levels",
+			"valid-key",
+			func(c *Client) error { _, err := c.GetSources(parameters{}); return err },
+			[]slog.Level{slog.LevelInfo, slog.LevelError},
+			"INFO",
+			map[string]interface{}{"endpoint": "sources"},
+		},
+		{
+			"Stream",
+			"valid-key",
+			func(c *Client) error {
+				_, err := c.StreamTopHeadlines(parameters{}, func(Article) error { return nil })
+				return err
+			},
+			nil,
+			"DEBUG",
+			map[string]interface{}{"endpoint": "top-headlines", "results": 0.0},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := []option{WithLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))}
+			if v.levels != nil {
+				opts = append(opts, WithLogLevels(v.levels[0], v.levels[1]))
+			}
+			c := New(v.apiKey, opts...)
+			c.APIUrl = testServer.URL
+			v.get(c)
+
+			if strings.Contains(buf.String(), v.apiKey) {
+				t.Fatalf("API key was logged '%v'", buf.String())
+			}
+			var line map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("Expected one JSON log line got '%v'", buf.String())
+			}
+			if line["level"] != v.expectedLevel {
+				t.Fatalf("Expected level %v got %v", v.expectedLevel, line["level"])
+			}
+			for k, e := range v.expected {
+				if line[k] != e {
+					t.Fatalf("Expected %v to be '%v' got '%v'", k, e, line[k])
+				}
+			}
+		})
+	}
+}
+
+func TestLoggerNotCalledForInvalidParameters(t *testing.T) {
+	var buf bytes.Buffer
+	c := New("TestAPIKey", WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+	c.GetTopHeadlines(parameters{"invalid": "string"})
+	if buf.Len() != 0 {
+		t.Fatalf("Expected nothing to be logged got '%v'", buf.String())
+	}
+}
+
+func TestLoggerResultCount(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok","totalResults":1,"articles":[{"title":"a"}]}`))
+	}))
+	defer testServer.Close()
+
+	var buf bytes.Buffer
+	c := New("TestAPIKey", WithLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	c.APIUrl = testServer.URL
+	c.GetTopHeadlines(parameters{})
+	if !strings.Contains(buf.String(), "results=1") || !strings.Contains(buf.String(), "shared=false") {
+		t.Fatalf("Unexpected log line '%v'", buf.String())
+	}
+}
+
+func TestLoggerCacheHit(t *testing.T) {
+	testServer := keyServer()
+	defer testServer.Close()
+
+	var buf bytes.Buffer
+	c := New("valid-key", WithCache(time.Minute), WithLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	c.APIUrl = testServer.URL
+	c.GetSources(parameters{})
+	c.GetSources(parameters{})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "cache_hit=false") || !strings.Contains(lines[1], "cache_hit=true") {
+		t.Fatalf("Expected the second call to be logged as a cache hit got '%v'", buf.String())
+	}
+}
@@ -0,0 +1,81 @@
+package newsapi
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+//Doer sends an HTTP request, *http.Client satisfies Doer
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+//DoerFunc lets an ordinary function be used as a Doer
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+//Do calls f(req)
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+//Middleware wraps a Doer to run code around each request sent to NewsAPI
+type Middleware func(next Doer) Doer
+
+//WithMiddleware adds middleware to the Client
+//Middleware added first is outermost, so it sees the request first and the response last
+func WithMiddleware(m ...Middleware) option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, m...)
+	}
+}
+
+//doer returns HTTPClient wrapped in the Client's middleware
+func (c *Client) doer() Doer {
+	var d Doer = c.HTTPClient
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		d = c.middleware[i](d)
+	}
+	return d
+}
+
+//LoggingMiddleware logs the method, URL, status and duration of each request to l
+func LoggingMiddleware(l *log.Logger) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.Do(req)
+			if err != nil {
+				l.Printf("%v %v failed after %v: %v", req.Method, redactURL(req.URL.String()), time.Since(start), redactError(err))
+				return res, err
+			}
+			l.Printf("%v %v %v in %v", req.Method, redactURL(req.URL.String()), res.StatusCode, time.Since(start))
+			return res, err
+		})
+	}
+}
+
+//TimingMiddleware calls fn with each request, how long it took to get a response and any error
+func TimingMiddleware(fn func(req *http.Request, d time.Duration, err error)) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.Do(req)
+			fn(req, time.Since(start), err)
+			return res, err
+		})
+	}
+}
+
+//HeaderMiddleware sets the headers in h on each request, for example tracing or audit headers
+func HeaderMiddleware(h http.Header) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for k, v := range h {
+				req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+			}
+			return next.Do(req)
+		})
+	}
+}
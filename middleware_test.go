@@ -0,0 +1,106 @@
+package newsapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer testServer.Close()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				res, err := next.Do(req)
+				calls = append(calls, name+" after")
+				return res, err
+			})
+		}
+	}
+
+	c := New("TestAPIKey", WithMiddleware(record("first"), record("second")), WithMiddleware(record("third")))
+	if _, _, err := c.makeRequest(context.Background(), testServer.URL); err != nil {
+		t.Fatal(err)
+	}
+	expected := "first before,second before,third before,third after,second after,first after"
+	if got := strings.Join(calls, ","); got != expected {
+		t.Fatalf("Expected '%v' got '%v'", expected, got)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	failure := errors.New("blocked by middleware")
+	block := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, failure
+		})
+	}
+	c := New("TestAPIKey", WithMiddleware(block))
+	_, _, err := c.makeRequest(context.Background(), "http://127.0.0.1:1")
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected '%v' got '%v'", failure, err)
+	}
+}
+
+func TestHeaderMiddleware(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Traceparent") != "00-abc-def-01" || r.Header.Get("X-Api-Key") != "TestAPIKey" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer testServer.Close()
+
+	c := New("TestAPIKey", WithMiddleware(HeaderMiddleware(http.Header{"traceparent": {"00-abc-def-01"}})))
+	if _, _, err := c.makeRequest(context.Background(), testServer.URL); err != nil {
+		t.Fatalf("Expected headers to be sent got %v", err)
+	}
+}
+
+func TestLoggingAndTimingMiddleware(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer testServer.Close()
+
+	var buf bytes.Buffer
+	var timed time.Duration
+	var timedURL string
+	c := New("TestAPIKey", WithMiddleware(
+		LoggingMiddleware(log.New(&buf, "", 0)),
+		TimingMiddleware(func(req *http.Request, d time.Duration, err error) {
+			timed = d
+			timedURL = req.URL.String()
+		}),
+	))
+	if _, _, err := c.makeRequest(context.Background(), testServer.URL+"/everything?q=test"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "GET "+testServer.URL+"/everything?q=test 200 in ") {
+		t.Fatalf("Unexpected log line '%v'", buf.String())
+	}
+	if strings.Contains(buf.String(), "TestAPIKey") {
+		t.Fatal("API key was logged")
+	}
+	if timed <= 0 || timedURL != testServer.URL+"/everything?q=test" {
+		t.Fatalf("Unexpected timing %v for '%v'", timed, timedURL)
+	}
+
+	buf.Reset()
+	c.makeRequest(context.Background(), "http://127.0.0.1:1")
+	if !strings.Contains(buf.String(), "GET http://127.0.0.1:1 failed after") {
+		t.Fatalf("Unexpected log line '%v'", buf.String())
+	}
+}
@@ -1,33 +1,38 @@
 package newsapi
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"time"
 )
 
-const (
-	apiPath           = "https://newsapi.org/v2" //Base path for API
-	apiHeadlinePath   = "/top-headlines?"
-	apiSourcePath     = "/sources?"
-	apiEverythingPath = "/everything?"
-)
+const apiPath = "https://newsapi.org/v2" //Base path for API
 
 type option func(*Client)
 
-type errorResponse struct {
-	Status  string `json:"status"`
-	Code    string `json:"code"`
-	Message string `json:"message"`
-}
-
 //Client contains the data required to make requests to NewsAPI
 type Client struct {
-	APIUrl     string
-	APIKey     string
-	HTTPClient *http.Client
+	APIUrl      string
+	APIKey      Secret
+	HTTPClient  *http.Client
+	coalescer   *coalescer
+	cache       *cache
+	keyPool     *KeyPool
+	plan        *Plan
+	logger      *logger
+	limiter     *quotaLimiter
+	strict      bool
+	checkQuery  bool
+	maxBodySize int64
+	middleware  []Middleware
+	hooks       []Hook
+	auth        AuthMethod
+	paths       map[Endpoint]string
+	dryRun      bool
+	defaults    map[string]string //Parameters added to requests that don't set them
+	configErr   error             //Error applying WithProfile, returned by every request
 }
 
 //Article contains data on an article returned from NewsAPI
@@ -39,6 +44,15 @@ type Article struct {
 	URL         string `json:"url"`
 	URLToImage  string `json:"urlToImage"`
 	PublishedAt string `json:"publishedAt"`
+	Content     string `json:"content"`
+}
+
+//ArticleKey identifies a, by its URL or by its source and title when it has no URL
+func ArticleKey(a Article) string {
+	if a.URL != "" {
+		return a.URL
+	}
+	return a.Source.Name + "\x00" + a.Title
 }
 
 //ArticleResults contains a slice of Articles along with it's length and the NewsAPI Status
@@ -46,25 +60,63 @@ type ArticleResults struct {
 	Status       string    `json:"status"`
 	TotalResults int64     `json:"totalResults"`
 	Articles     []Article `json:"articles"`
+	Response     *Response `json:"-"` //Metadata about the reply, nil when no reply was received
 }
 
 //Source contains data on a source returned from NewsAPI
+//Sources attached to an Article only contain the ID and Name
 type Source struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Language    string `json:"language,omitempty"`
+	Country     string `json:"country,omitempty"`
 }
 
 //SourceResults contains a slice of Articles the NewsAPI Status
 type SourceResults struct {
-	Status string   `json:"status"`
-	Source []Source `json:"sources"`
+	Status   string    `json:"status"`
+	Source   []Source  `json:"sources"`
+	Response *Response `json:"-"` //Metadata about the reply, nil when no reply was received
+}
+
+//topHeadlinesParameters lists the parameters allowed by the top headlines endpoint and their types
+var topHeadlinesParameters = allowedParameters{
+	"country":  "string",
+	"category": "string",
+	"sources":  "[]string",
+	"q":        "string",
+	"pageSize": "int",
+	"page":     "int",
+}
+
+//everythingParameters lists the parameters allowed by the everything endpoint and their types
+var everythingParameters = allowedParameters{
+	"q":        "string",
+	"sources":  "[]string",
+	"domains":  "[]string",
+	"from":     "string",
+	"to":       "string",
+	"language": "string",
+	"sortBy":   "string",
+	"pageSize": "int",
+	"page":     "int",
+}
+
+//sourcesParameters lists the parameters allowed by the sources endpoint and their types
+var sourcesParameters = allowedParameters{
+	"country":  "string",
+	"category": "string",
+	"language": "string",
 }
 
 //New creates our Client struct that we use to make requests
 func New(apiKey string, options ...option) *Client {
 	c := Client{
 		APIUrl: apiPath,
-		APIKey: apiKey,
+		APIKey: Secret(apiKey),
 		HTTPClient: &http.Client{
 			Timeout: time.Second * 30,
 		},
@@ -76,33 +128,139 @@ func New(apiKey string, options ...option) *Client {
 }
 
 //makeRequest handles all our requests to newsapi
-//Takes a request URL and returns the replied body and an error from either the request or NewsAPI
-func (c *Client) makeRequest(endpoint string) ([]byte, error) {
-	req, err := http.NewRequest("GET", endpoint, nil)
+//Takes a request URL and returns the replied body, the response metadata and an error from either the request or NewsAPI
+//When caching is enabled fresh cached replies are returned without a request
+//When coalescing is enabled concurrent calls for the same URL and key share a single upstream request
+func (c *Client) makeRequest(ctx context.Context, endpoint string) ([]byte, *Response, error) {
+	if c.dryRun {
+		return nil, nil, c.dryRunError(endpoint)
+	}
+	key := endpoint + "\x00" + string(c.APIKey)
+	if c.cache != nil {
+		if b, r, ok := c.cache.get(key); ok {
+			return b, r, nil
+		}
+	}
+	b, r, err := c.coalesce(ctx, key, endpoint)
+	if err == nil && c.cache != nil {
+		c.cache.set(key, b, r)
+	}
+	return b, r, err
+}
+
+//coalesce fetches endpoint, sharing the request with concurrent callers using the same key when coalescing is enabled
+//A shared request keeps going while any caller is waiting on it, each caller stops waiting when its own ctx is done
+func (c *Client) coalesce(ctx context.Context, key, endpoint string) ([]byte, *Response, error) {
+	if c.coalescer == nil {
+		return c.fetch(ctx, endpoint)
+	}
+	b, r, shared, err := c.coalescer.do(ctx, key, func(ctx context.Context) ([]byte, *Response, error) {
+		return c.fetch(ctx, endpoint)
+	})
+	if shared && r != nil {
+		sr := *r
+		sr.Shared = true
+		r = &sr
+	}
+	return b, r, err
+}
+
+//fetch sends a request to newsapi and returns the replied body
+func (c *Client) fetch(ctx context.Context, endpoint string) ([]byte, *Response, error) {
+	var b []byte
+	r, err := c.withKey(func(key Secret) (*Response, error) {
+		if err := c.takeQuota(); err != nil {
+			return nil, err
+		}
+		var r *Response
+		var err error
+		b, r, err = c.send(ctx, endpoint, key)
+		return r, err
+	})
+	return b, r, err
+}
+
+//takeQuota counts a request against the Client's plan quota, returning ErrQuotaExceeded when none is left
+func (c *Client) takeQuota() error {
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.take()
+}
+
+//withKey calls fn with the Client's API key
+//When a key pool is configured fn is retried with the next key while keys report exhausted or rate limited
+//Once no keys are left ErrNoKeysAvailable is returned with the last reply, so its Attempts still counts the keys tried
+func (c *Client) withKey(fn func(key Secret) (*Response, error)) (*Response, error) {
+	if c.keyPool == nil {
+		return fn(c.APIKey)
+	}
+	var last *Response
+	for attempt := 1; ; attempt++ {
+		k, err := c.keyPool.acquire()
+		if err != nil {
+			return last, err
+		}
+		r, err := fn(k.key)
+		if r != nil {
+			r.Attempts = attempt
+		}
+		if c.keyPool.release(k, err) {
+			last = r
+			continue
+		}
+		return r, err
+	}
+}
+
+//send makes a single request to newsapi using the given key and reads the whole body
+//The response metadata is returned whenever a reply was received, including with an APIError
+func (c *Client) send(ctx context.Context, endpoint string, key Secret) ([]byte, *Response, error) {
+	start := time.Now()
+	res, r, err := c.open(ctx, endpoint, key)
 	if err != nil {
-		return nil, err
+		return nil, r, err
 	}
-	if c.APIKey == "" {
-		return nil, errors.New("Expected API key got nothing")
+	defer closeBody(res.Body)
+	b, err := ioutil.ReadAll(c.limitBody(res.Body))
+	r.Latency = time.Since(start)
+	if err == ErrBodyTooLarge {
+		return nil, r, err
 	}
-	req.Header.Add("X-Api-Key", c.APIKey)
-	res, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, r, &TransportError{Err: err}
 	}
-	b, err := ioutil.ReadAll(res.Body)
+	return b, r, nil
+}
+
+//open makes a single request to newsapi using the given key and returns once the headers are read
+//Error replies are read and returned as an APIError or UpstreamError, otherwise the caller must close the body with closeBody
+func (c *Client) open(ctx context.Context, endpoint string, key Secret) (*http.Response, *Response, error) {
+	req, err := c.newRequest(ctx, endpoint, key)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	if res.StatusCode != 200 {
-		var errResp errorResponse
-		err := json.Unmarshal(b, &errResp)
-		if err != nil {
-			return nil, err
-		}
-		return nil, errors.New(errResp.Message)
+	start := time.Now()
+	res, err := c.doer().Do(req)
+	if err != nil {
+		return nil, nil, &TransportError{Err: redactError(err)}
 	}
-	return b, nil
+	r := newResponse(res, time.Since(start))
+	if res.StatusCode == 200 {
+		return res, r, nil
+	}
+	defer closeBody(res.Body)
+	b, err := ioutil.ReadAll(c.limitBody(res.Body))
+	if err != nil {
+		return nil, r, &TransportError{Err: err}
+	}
+	return nil, r, errorFromResponse(res, b)
+}
+
+//closeBody drains any unread part of a reply body before closing it so the connection can be reused
+func closeBody(body io.ReadCloser) {
+	io.CopyN(ioutil.Discard, body, maxDrain)
+	body.Close()
 }
 
 //GetTopHeadlines is used to interact with the TopHeadlines API endpoint
@@ -115,28 +273,24 @@ func (c *Client) makeRequest(endpoint string) ([]byte, error) {
 // pageSize - int
 // page - int
 func (c *Client) GetTopHeadlines(p parameters) (ArticleResults, error) {
-	var o ArticleResults
-	ap := allowedParameters{ //List of allowed parameters and their allowed types
-		"country":  "string",
-		"category": "string",
-		"sources":  "[]string",
-		"q":        "string",
-		"pageSize": "int",
-		"page":     "int"}
-	u, err := p.buildURL(c.APIUrl+apiHeadlinePath, &ap)
-	if err != nil {
-		return o, err
-	}
+	return c.GetTopHeadlinesContext(context.Background(), p)
+}
 
-	d, err := c.makeRequest(u)
+//GetTopHeadlinesContext works like GetTopHeadlines, the request is cancelled when ctx is done
+func (c *Client) GetTopHeadlinesContext(ctx context.Context, p parameters) (ArticleResults, error) {
+	var o ArticleResults
+	u, err := c.requestURL(EndpointTopHeadlines, p)
 	if err != nil {
 		return o, err
 	}
-	err = json.Unmarshal(d, &o)
-	if err != nil {
-		return o, nil
+	start := time.Now()
+	d, r, err := c.makeRequest(ctx, u)
+	o.Response = r
+	if err == nil {
+		err = c.decode(d, &o)
 	}
-	return o, nil
+	c.observe(ctx, Event{Endpoint: EndpointTopHeadlines, Start: start, Response: r, Results: len(o.Articles), Err: err}, u)
+	return o, err
 }
 
 //GetEverything is used to interact with the GetEverything API endpoint
@@ -147,35 +301,29 @@ func (c *Client) GetTopHeadlines(p parameters) (ArticleResults, error) {
 // domains - []string
 // from - string
 // to - string
-// lanague - string
+// language - string
 // sortBy - string
 // pageSize - int
 // page - int
 func (c *Client) GetEverything(p parameters) (ArticleResults, error) {
+	return c.GetEverythingContext(context.Background(), p)
+}
+
+//GetEverythingContext works like GetEverything, the request is cancelled when ctx is done
+func (c *Client) GetEverythingContext(ctx context.Context, p parameters) (ArticleResults, error) {
 	var o ArticleResults
-	ap := allowedParameters{ //List of allowed parameters and their allowed types
-		"q":        "string",
-		"sources":  "[]string",
-		"domains":  "[]string",
-		"from":     "string",
-		"to":       "string",
-		"lanague":  "string",
-		"sortBy":   "string",
-		"pageSize": "int",
-		"page":     "int"}
-	u, err := p.buildURL(c.APIUrl+apiEverythingPath, &ap)
-	if err != nil {
-		return o, err
-	}
-	d, err := c.makeRequest(u)
+	u, err := c.requestURL(EndpointEverything, p)
 	if err != nil {
 		return o, err
 	}
-	err = json.Unmarshal(d, &o)
-	if err != nil {
-		return o, nil
+	start := time.Now()
+	d, r, err := c.makeRequest(ctx, u)
+	o.Response = r
+	if err == nil {
+		err = c.decode(d, &o)
 	}
-	return o, nil
+	c.observe(ctx, Event{Endpoint: EndpointEverything, Start: start, Response: r, Results: len(o.Articles), Err: err}, u)
+	return o, err
 }
 
 //GetSources is used to interact with the GetSources API endpoint
@@ -183,24 +331,24 @@ func (c *Client) GetEverything(p parameters) (ArticleResults, error) {
 //This takes the following paramaters with the accepted types
 // country - string
 // category - string
-// lanague - string
+// language - string
 func (c *Client) GetSources(p parameters) (SourceResults, error) {
+	return c.GetSourcesContext(context.Background(), p)
+}
+
+//GetSourcesContext works like GetSources, the request is cancelled when ctx is done
+func (c *Client) GetSourcesContext(ctx context.Context, p parameters) (SourceResults, error) {
 	var o SourceResults
-	ap := allowedParameters{ //List of allowed parameters and their allowed types
-		"country":  "string",
-		"category": "string",
-		"lanague":  "string"}
-	u, err := p.buildURL(c.APIUrl+apiSourcePath, &ap)
+	u, err := c.requestURL(EndpointSources, p)
 	if err != nil {
 		return o, err
 	}
-	d, err := c.makeRequest(u)
-	if err != nil {
-		return o, err
-	}
-	err = json.Unmarshal(d, &o)
-	if err != nil {
-		return o, nil
+	start := time.Now()
+	d, r, err := c.makeRequest(ctx, u)
+	o.Response = r
+	if err == nil {
+		err = c.decode(d, &o)
 	}
-	return o, nil
+	c.observe(ctx, Event{Endpoint: EndpointSources, Start: start, Response: r, Results: len(o.Source), Err: err}, u)
+	return o, err
 }
@@ -1,6 +1,8 @@
 package newsapi
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -68,7 +70,7 @@ func TestMakeRequest(t *testing.T) {
 		t.Run(v.testName, func(t *testing.T) {
 			c := New(v.apiKey)
 			c.HTTPClient.Timeout = time.Second * 2
-			d, err := c.makeRequest(v.endPoint)
+			d, _, err := c.makeRequest(context.Background(), v.endPoint)
 			if err != nil {
 				if !v.expectedError {
 					t.Fatalf("Unexpected error - %v", err.Error())
@@ -237,3 +239,53 @@ func TestGetSources(t *testing.T) {
 		})
 	}
 }
+
+func TestContextCancel(t *testing.T) {
+	done := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer testServer.Close()
+	defer close(done)
+
+	c := New("TestAPIKey")
+	c.APIUrl = testServer.URL
+	tt := []struct {
+		testName string
+		call     func(ctx context.Context) error
+	}{
+		{"Top headlines", func(ctx context.Context) error {
+			_, err := c.GetTopHeadlinesContext(ctx, parameters{"country": "gb"})
+			return err
+		}},
+		{"Everything", func(ctx context.Context) error {
+			_, err := c.GetEverythingContext(ctx, parameters{"q": "go"})
+			return err
+		}},
+		{"Sources", func(ctx context.Context) error {
+			_, err := c.GetSourcesContext(ctx, nil)
+			return err
+		}},
+		{"Stream", func(ctx context.Context) error {
+			_, err := c.StreamEverythingContext(ctx, parameters{"q": "go"}, func(Article) error { return nil })
+			return err
+		}},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			err := v.call(ctx)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Expected context.DeadlineExceeded got %v", err)
+			}
+			if time.Since(start) > 5*time.Second {
+				t.Fatalf("Expected the request to stop when the context was done, took %v", time.Since(start))
+			}
+		})
+	}
+}
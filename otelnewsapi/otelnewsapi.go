@@ -0,0 +1,166 @@
+//Package otelnewsapi records OpenTelemetry spans and metrics for calls made by a newsapi Client
+package otelnewsapi
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Oliver-Fish/newsapi"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//instrumentationName identifies this package to tracer and meter providers
+const instrumentationName = "github.com/Oliver-Fish/newsapi/otelnewsapi"
+
+//Attribute keys set on spans and metrics
+const (
+	EndpointKey = attribute.Key("newsapi.endpoint")
+	CountryKey  = attribute.Key("newsapi.country")
+	CategoryKey = attribute.Key("newsapi.category")
+	PageKey     = attribute.Key("newsapi.page")
+	ResultsKey  = attribute.Key("newsapi.results")
+	CodeKey     = attribute.Key("newsapi.error.code")
+	OutcomeKey  = attribute.Key("newsapi.outcome")
+	CacheHitKey = attribute.Key("newsapi.cache_hit")
+	StatusKey   = attribute.Key("http.response.status_code")
+)
+
+type config struct {
+	tp trace.TracerProvider
+	mp metric.MeterProvider
+}
+
+//Option configures Instrument
+type Option func(*config)
+
+//WithTracerProvider sets the TracerProvider spans are created with, the global provider is used by default
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tp = tp
+	}
+}
+
+//WithMeterProvider sets the MeterProvider metrics are recorded with, the global provider is used by default
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.mp = mp
+	}
+}
+
+type instrumentation struct {
+	tracer    trace.Tracer
+	requests  metric.Int64Counter
+	duration  metric.Float64Histogram
+	shared    metric.Int64Counter
+	cacheHits metric.Int64Counter
+}
+
+//Instrument adds a hook to c that creates a span and records metrics for each call
+//Spans are children of the span in the context passed to the Client's Context methods, calls without a context start a new trace
+//The following metrics are recorded
+// newsapi.client.requests - calls by endpoint, outcome and NewsAPI error code
+// newsapi.client.duration - call latency in seconds by endpoint
+// newsapi.client.shared - calls answered by a coalesced in-flight request
+// newsapi.client.cache_hits - calls answered from the Client's cache
+// newsapi.client.quota.remaining - requests left in the Client's plan quota
+func Instrument(c *newsapi.Client, opts ...Option) error {
+	cfg := config{tp: otel.GetTracerProvider(), mp: otel.GetMeterProvider()}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	meter := cfg.mp.Meter(instrumentationName)
+	i := instrumentation{tracer: cfg.tp.Tracer(instrumentationName)}
+	var err error
+	i.requests, err = meter.Int64Counter("newsapi.client.requests",
+		metric.WithDescription("Calls made to NewsAPI"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return err
+	}
+	i.duration, err = meter.Float64Histogram("newsapi.client.duration",
+		metric.WithDescription("Duration of calls made to NewsAPI"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	i.shared, err = meter.Int64Counter("newsapi.client.shared",
+		metric.WithDescription("Calls answered with the reply of a coalesced in-flight request"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return err
+	}
+	i.cacheHits, err = meter.Int64Counter("newsapi.client.cache_hits",
+		metric.WithDescription("Calls answered from the Client's cache without a request"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return err
+	}
+	_, err = meter.Int64ObservableGauge("newsapi.client.quota.remaining",
+		metric.WithDescription("Requests left in the plan quota"),
+		metric.WithUnit("{request}"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			if r, ok := c.QuotaRemaining(); ok {
+				o.Observe(int64(r))
+			}
+			return nil
+		}))
+	if err != nil {
+		return err
+	}
+	c.AddHook(i.record)
+	return nil
+}
+
+//record creates a span and records metrics for e, the span is a child of any span in the context the call was made with
+func (i instrumentation) record(e newsapi.Event) {
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	attrs := []attribute.KeyValue{EndpointKey.String(string(e.Endpoint))}
+	for k, key := range map[string]attribute.Key{"country": CountryKey, "category": CategoryKey} {
+		if v := e.Query.Get(k); v != "" {
+			attrs = append(attrs, key.String(v))
+		}
+	}
+	if p, err := strconv.Atoi(e.Query.Get("page")); err == nil {
+		attrs = append(attrs, PageKey.Int(p))
+	}
+
+	_, span := i.tracer.Start(ctx, "newsapi "+string(e.Endpoint),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(e.Start),
+		trace.WithAttributes(attrs...))
+	if e.Response != nil {
+		span.SetAttributes(StatusKey.Int(e.Response.StatusCode), CacheHitKey.Bool(e.Response.FromCache))
+	}
+	outcome := "ok"
+	if e.Err != nil {
+		outcome = "error"
+		span.RecordError(e.Err)
+		span.SetStatus(codes.Error, e.Err.Error())
+		if code := e.Code(); code != "" {
+			span.SetAttributes(CodeKey.String(code))
+		}
+	} else {
+		span.SetAttributes(ResultsKey.Int(e.Results))
+	}
+	span.End(trace.WithTimestamp(e.Start.Add(e.Duration)))
+
+	endpoint := metric.WithAttributes(EndpointKey.String(string(e.Endpoint)))
+	i.requests.Add(ctx, 1, metric.WithAttributes(
+		EndpointKey.String(string(e.Endpoint)),
+		OutcomeKey.String(outcome),
+		CodeKey.String(e.Code())))
+	i.duration.Record(ctx, e.Duration.Seconds(), endpoint)
+	if e.Response != nil && e.Response.Shared {
+		i.shared.Add(ctx, 1, endpoint)
+	}
+	if e.Response != nil && e.Response.FromCache {
+		i.cacheHits.Add(ctx, 1, endpoint)
+	}
+}
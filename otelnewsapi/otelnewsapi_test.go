@@ -0,0 +1,237 @@
+package otelnewsapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func fakeServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("country") == "us" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","code":"rateLimited","message":"You have been rate limited."}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ok","totalResults":2,"articles":[{"title":"a"},{"title":"b"}]}`))
+	}))
+}
+
+func TestInstrument(t *testing.T) {
+	testServer := fakeServer()
+	defer testServer.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	c := newsapi.New("TestAPIKey", newsapi.WithPlan(newsapi.Plan{Name: "Test", Quota: 10, QuotaPeriod: time.Hour}))
+	c.APIUrl = testServer.URL
+	if err := Instrument(c, WithTracerProvider(tp), WithMeterProvider(mp)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.GetTopHeadlines(map[string]interface{}{"country": "gb", "category": "business", "page": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetTopHeadlines(map[string]interface{}{"country": "us"}); err == nil {
+		t.Fatal("Expected error got nil")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans got %d", len(spans))
+	}
+	tt := []struct {
+		testName       string
+		span           tracetest.SpanStub
+		expectedAttrs  map[attribute.Key]attribute.Value
+		expectedStatus codes.Code
+	}{
+		{"Success span", spans[0], map[attribute.Key]attribute.Value{
+			EndpointKey: attribute.StringValue("top-headlines"),
+			CountryKey:  attribute.StringValue("gb"),
+			CategoryKey: attribute.StringValue("business"),
+			PageKey:     attribute.IntValue(2),
+			ResultsKey:  attribute.IntValue(2),
+			StatusKey:   attribute.IntValue(200),
+		}, codes.Unset},
+		{"Error span", spans[1], map[attribute.Key]attribute.Value{
+			EndpointKey: attribute.StringValue("top-headlines"),
+			CountryKey:  attribute.StringValue("us"),
+			CodeKey:     attribute.StringValue("rateLimited"),
+			StatusKey:   attribute.IntValue(429),
+		}, codes.Error},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			if v.span.Name != "newsapi top-headlines" {
+				t.Fatalf("Unexpected span name '%v'", v.span.Name)
+			}
+			got := make(map[attribute.Key]attribute.Value)
+			for _, a := range v.span.Attributes {
+				got[a.Key] = a.Value
+			}
+			for k, e := range v.expectedAttrs {
+				if got[k] != e {
+					t.Fatalf("Expected %v to be '%v' got '%v'", k, e.Emit(), got[k].Emit())
+				}
+			}
+			if v.span.Status.Code != v.expectedStatus {
+				t.Fatalf("Expected status %v got %v", v.expectedStatus, v.span.Status.Code)
+			}
+			if !v.span.EndTime.After(v.span.StartTime) {
+				t.Fatalf("Expected span to have a duration got %v to %v", v.span.StartTime, v.span.EndTime)
+			}
+		})
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	requests, ok := metrics["newsapi.client.requests"].(metricdata.Sum[int64])
+	if !ok || len(requests.DataPoints) != 2 {
+		t.Fatalf("Expected 2 request data points got %+v", metrics["newsapi.client.requests"])
+	}
+	for _, dp := range requests.DataPoints {
+		outcome, _ := dp.Attributes.Value(OutcomeKey)
+		code, _ := dp.Attributes.Value(CodeKey)
+		if dp.Value != 1 || (outcome.AsString() == "error") != (code.AsString() == "rateLimited") {
+			t.Fatalf("Unexpected request data point %+v", dp)
+		}
+	}
+	duration, ok := metrics["newsapi.client.duration"].(metricdata.Histogram[float64])
+	if !ok || len(duration.DataPoints) != 1 || duration.DataPoints[0].Count != 2 {
+		t.Fatalf("Expected 2 durations recorded got %+v", metrics["newsapi.client.duration"])
+	}
+	quota, ok := metrics["newsapi.client.quota.remaining"].(metricdata.Gauge[int64])
+	if !ok || len(quota.DataPoints) != 1 || quota.DataPoints[0].Value != 8 {
+		t.Fatalf("Expected 8 quota remaining got %+v", metrics["newsapi.client.quota.remaining"])
+	}
+}
+
+func TestInstrumentSharedRequests(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	i := instrumentation{}
+	meter := mp.Meter(instrumentationName)
+	i.tracer = sdktrace.NewTracerProvider().Tracer(instrumentationName)
+	i.requests, _ = meter.Int64Counter("newsapi.client.requests")
+	i.duration, _ = meter.Float64Histogram("newsapi.client.duration")
+	i.shared, _ = meter.Int64Counter("newsapi.client.shared")
+
+	i.record(newsapi.Event{Endpoint: newsapi.EndpointEverything, Start: time.Now(), Response: &newsapi.Response{StatusCode: 200, Shared: true}})
+	i.record(newsapi.Event{Endpoint: newsapi.EndpointEverything, Start: time.Now(), Response: &newsapi.Response{StatusCode: 200}})
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != "newsapi.client.shared" {
+			continue
+		}
+		if s := m.Data.(metricdata.Sum[int64]); s.DataPoints[0].Value != 1 {
+			t.Fatalf("Expected 1 shared call got %d", s.DataPoints[0].Value)
+		}
+		return
+	}
+	t.Fatal("Expected shared metric to be recorded")
+}
+
+func TestInstrumentParentSpan(t *testing.T) {
+	testServer := fakeServer()
+	defer testServer.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	c := newsapi.New("TestAPIKey")
+	c.APIUrl = testServer.URL
+	if err := Instrument(c, WithTracerProvider(tp), WithMeterProvider(sdkmetric.NewMeterProvider())); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "handler")
+	if _, err := c.GetTopHeadlinesContext(ctx, map[string]interface{}{"country": "gb"}); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+	c.GetTopHeadlines(map[string]interface{}{"country": "gb"})
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans got %d", len(spans))
+	}
+	if spans[0].Parent.SpanID() != parent.SpanContext().SpanID() || spans[0].SpanContext.TraceID() != parent.SpanContext().TraceID() {
+		t.Fatalf("Expected the NewsAPI span to be a child of the caller's span got parent %v", spans[0].Parent.SpanID())
+	}
+	if spans[2].Parent.IsValid() {
+		t.Fatalf("Expected a call without a span in its context to start a new trace got parent %v", spans[2].Parent.SpanID())
+	}
+}
+
+func TestInstrumentCacheHits(t *testing.T) {
+	testServer := fakeServer()
+	defer testServer.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	c := newsapi.New("TestAPIKey", newsapi.WithCache(time.Minute))
+	c.APIUrl = testServer.URL
+	if err := Instrument(c, WithTracerProvider(tp), WithMeterProvider(mp)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetTopHeadlines(map[string]interface{}{"country": "gb"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans got %d", len(spans))
+	}
+	for i, expected := range []bool{false, true} {
+		for _, a := range spans[i].Attributes {
+			if a.Key == CacheHitKey && a.Value.AsBool() != expected {
+				t.Fatalf("Expected span %d %v to be %v got %v", i, CacheHitKey, expected, a.Value.AsBool())
+			}
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != "newsapi.client.cache_hits" {
+			continue
+		}
+		if s := m.Data.(metricdata.Sum[int64]); s.DataPoints[0].Value != 1 {
+			t.Fatalf("Expected 1 cache hit got %d", s.DataPoints[0].Value)
+		}
+		return
+	}
+	t.Fatal("Expected cache hit metric to be recorded")
+}
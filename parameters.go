@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -32,6 +33,35 @@ var allowedLanguages = map[string]struct{}{"ar": struct{}{}, "de": struct{}{}, "
 
 var allowedSortByOptions = map[string]struct{}{"publishedAt": struct{}{}, "relevancy": struct{}{}, "popularity": struct{}{}}
 
+//Countries returns the 2-letter country codes NewsAPI accepts in alphabetical order
+func Countries() []string {
+	return sortedKeys(allowedCountryCodes)
+}
+
+//Categories returns the news categories NewsAPI accepts in alphabetical order
+func Categories() []string {
+	return sortedKeys(allowedCategories)
+}
+
+//Languages returns the 2-letter language codes NewsAPI accepts in alphabetical order
+func Languages() []string {
+	return sortedKeys(allowedLanguages)
+}
+
+//SortByOptions returns the sortBy values NewsAPI accepts in alphabetical order
+func SortByOptions() []string {
+	return sortedKeys(allowedSortByOptions)
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	l := make([]string, 0, len(m))
+	for k := range m {
+		l = append(l, k)
+	}
+	sort.Strings(l)
+	return l
+}
+
 func (p *parameters) buildURL(bURL string, ap *allowedParameters) (string, error) {
 	err := ap.verify(*p)
 	if err != nil {
@@ -79,6 +109,12 @@ func (p *parameters) buildURL(bURL string, ap *allowedParameters) (string, error
 				return "", errors.New("Empty list of domains")
 			}
 			u.Add(k, s)
+		case "from", "to":
+			s := fmt.Sprintf("%v", v)
+			if _, err := parseDate(s); err != nil {
+				return "", err
+			}
+			u.Add(k, s)
 		case "q":
 			s := fmt.Sprintf("%v", v)
 			if s == "" {
@@ -87,6 +123,9 @@ func (p *parameters) buildURL(bURL string, ap *allowedParameters) (string, error
 			u.Add(k, s)
 		case "pageSize", "page":
 			i, ok := v.(int)
+			if ok && i < 1 {
+				return "", fmt.Errorf("Expected %v of at least 1 got %d", k, i)
+			}
 			if ok {
 				u.Add(k, strconv.Itoa(i))
 			}
@@ -119,6 +119,30 @@ func TestBuildURL(t *testing.T) {
 			"",
 			"Empty list of domains",
 		},
+		{
+			"from parameter - valid",
+			"https://newsapi.org/v2/",
+			parameters{"from": "2024-01-01"},
+			allowedParameters{"from": "string"},
+			"https://newsapi.org/v2/from=2024-01-01",
+			"",
+		},
+		{
+			"to parameter - valid",
+			"https://newsapi.org/v2/",
+			parameters{"to": "2024-01-02T15:04:05"},
+			allowedParameters{"to": "string"},
+			"https://newsapi.org/v2/to=2024-01-02T15%3A04%3A05",
+			"",
+		},
+		{
+			"from parameter - Invalid",
+			"https://newsapi.org/v2/",
+			parameters{"from": "yesterday"},
+			allowedParameters{"from": "string"},
+			"",
+			"Invalid date yesterday expected ISO 8601 format",
+		},
 		{
 			"query parameter - valid",
 			"https://newsapi.org/v2/",
@@ -135,6 +159,30 @@ func TestBuildURL(t *testing.T) {
 			"",
 			"Expected query got empty string",
 		},
+		{
+			"query parameter - syntax not checked",
+			"https://newsapi.org/v2/",
+			parameters{"q": "bitcoin AND"},
+			allowedParameters{"q": "string"},
+			"https://newsapi.org/v2/q=bitcoin+AND",
+			"",
+		},
+		{
+			"pageSize parameter - Invalid",
+			"https://newsapi.org/v2/",
+			parameters{"pageSize": 0},
+			allowedParameters{"pageSize": "int"},
+			"",
+			"Expected pageSize of at least 1 got 0",
+		},
+		{
+			"page parameter - Invalid",
+			"https://newsapi.org/v2/",
+			parameters{"page": -1},
+			allowedParameters{"page": "int"},
+			"",
+			"Expected page of at least 1 got -1",
+		},
 		{
 			"pageSize parameter - valid",
 			"https://newsapi.org/v2/",
@@ -224,3 +272,35 @@ func TestInterfaceToStringList(t *testing.T) {
 		})
 	}
 }
+
+func TestAllowedValues(t *testing.T) {
+	tt := []struct {
+		testName string
+		values   []string
+		length   int
+		first    string
+		contains string
+	}{
+		{"Countries", Countries(), 54, "ae", "gb"},
+		{"Categories", Categories(), 7, "business", "technology"},
+		{"Languages", Languages(), 14, "ar", "en"},
+		{"Sort by", SortByOptions(), 3, "popularity", "publishedAt"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			if len(v.values) != v.length || v.values[0] != v.first {
+				t.Fatalf("Expected %v values starting with %v got %v", v.length, v.first, v.values)
+			}
+			found := false
+			for i, s := range v.values {
+				if i > 0 && v.values[i-1] >= s {
+					t.Fatalf("Expected values in order got %v", v.values)
+				}
+				found = found || s == v.contains
+			}
+			if !found {
+				t.Fatalf("Expected %v in %v", v.contains, v.values)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package newsapi
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//Plan describes the limits of a NewsAPI subscription plan
+//https://newsapi.org/pricing
+type Plan struct {
+	Name        string
+	Quota       int           //Requests allowed per QuotaPeriod, zero means unlimited
+	QuotaPeriod time.Duration //Window the Quota applies to, a day when zero
+	MaxHistory  time.Duration //How far back the from parameter can reach, zero means unlimited
+	MaxResults  int           //Maximum number of results reachable through paging, zero means unlimited
+	Delay       time.Duration //How far behind real time articles are made available
+}
+
+//DeveloperPlan is the free NewsAPI plan
+var DeveloperPlan = Plan{
+	Name:        "Developer",
+	Quota:       100,
+	QuotaPeriod: 24 * time.Hour,
+	MaxHistory:  30 * 24 * time.Hour,
+	MaxResults:  100,
+	Delay:       24 * time.Hour,
+}
+
+//BusinessPlan is the paid NewsAPI plan
+var BusinessPlan = Plan{
+	Name:        "Business",
+	Quota:       250000,
+	QuotaPeriod: 30 * 24 * time.Hour,
+	MaxHistory:  5 * 365 * 24 * time.Hour,
+}
+
+//ErrQuotaExceeded is returned when a request would go over the Client's plan quota
+var ErrQuotaExceeded = errors.New("Plan request quota exceeded")
+
+//defaultPageSize is the page size NewsAPI uses when pageSize isn't passed
+const defaultPageSize = 20
+
+//dateFormats are the from and to formats accepted by NewsAPI
+var dateFormats = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+//WithPlan makes the Client enforce the limits of p before sending requests
+func WithPlan(p Plan) option {
+	return func(c *Client) {
+		if p.Quota > 0 && p.QuotaPeriod <= 0 {
+			p.QuotaPeriod = 24 * time.Hour
+		}
+		c.plan = &p
+		if p.Quota > 0 {
+			c.limiter = &quotaLimiter{quota: p.Quota, period: p.QuotaPeriod, now: time.Now}
+		}
+	}
+}
+
+//QuotaRemaining returns the number of requests left in the current quota period
+//The returned bool is false when the Client has no plan quota
+func (c *Client) QuotaRemaining() (int, bool) {
+	if c.limiter == nil {
+		return 0, false
+	}
+	return c.limiter.remaining(), true
+}
+
+//checkPlan validates the date range and paging in p against the Client's plan
+func (c *Client) checkPlan(p parameters) error {
+	if c.plan == nil {
+		return nil
+	}
+	if c.plan.MaxHistory > 0 {
+		if v, ok := p["from"].(string); ok {
+			from, err := parseDate(v)
+			if err != nil {
+				return err
+			}
+			oldest := time.Now().Add(-c.plan.MaxHistory)
+			if from.Before(oldest) {
+				return fmt.Errorf("From date %v is older than the %v plan allows, oldest is %v", v, c.plan.Name, oldest.Format("2006-01-02"))
+			}
+		}
+	}
+	if v, ok := p["to"].(string); ok {
+		if _, err := parseDate(v); err != nil {
+			return err
+		}
+	}
+	if c.plan.MaxResults > 0 {
+		page, pageSize := 1, defaultPageSize
+		if v, ok := p["page"].(int); ok {
+			page = v
+		}
+		if v, ok := p["pageSize"].(int); ok {
+			pageSize = v
+		}
+		if page*pageSize > c.plan.MaxResults {
+			return fmt.Errorf("Page %d with page size %d goes past the %v plan maximum of %d results", page, pageSize, c.plan.Name, c.plan.MaxResults)
+		}
+	}
+	return nil
+}
+
+//parseDate parses a from or to parameter in any of the formats NewsAPI accepts
+func parseDate(s string) (time.Time, error) {
+	for _, f := range dateFormats {
+		if t, err := time.Parse(f, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid date %v expected ISO 8601 format", s)
+}
+
+//quotaLimiter counts requests in fixed windows of period
+type quotaLimiter struct {
+	mu     sync.Mutex
+	quota  int
+	period time.Duration
+	used   int
+	start  time.Time
+	now    func() time.Time
+}
+
+//take counts a request against the quota, returning ErrQuotaExceeded when none are left
+func (l *quotaLimiter) take() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reset()
+	if l.used >= l.quota {
+		return ErrQuotaExceeded
+	}
+	l.used++
+	return nil
+}
+
+func (l *quotaLimiter) remaining() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reset()
+	return l.quota - l.used
+}
+
+//reset starts a new window once the previous one has passed
+func (l *quotaLimiter) reset() {
+	now := l.now()
+	if now.Sub(l.start) >= l.period {
+		l.start = now
+		l.used = 0
+	}
+}
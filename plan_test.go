@@ -0,0 +1,104 @@
+package newsapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckPlan(t *testing.T) {
+	recent := time.Now().Add(-48 * time.Hour).Format("2006-01-02")
+	old := time.Now().Add(-60 * 24 * time.Hour).Format("2006-01-02")
+	custom := Plan{Name: "Custom", MaxResults: 50}
+
+	tt := []struct {
+		testName      string
+		plan          *Plan
+		parameters    parameters
+		expectedError bool
+	}{
+		{"No plan", nil, parameters{"from": old, "page": 50}, false},
+		{"Developer - recent from", &DeveloperPlan, parameters{"from": recent}, false},
+		{"Developer - from too old", &DeveloperPlan, parameters{"from": old}, true},
+		{"Developer - invalid from", &DeveloperPlan, parameters{"from": "yesterday"}, true},
+		{"Developer - invalid to", &DeveloperPlan, parameters{"to": "tomorrow"}, true},
+		{"Developer - RFC3339 from", &DeveloperPlan, parameters{"from": time.Now().Add(-time.Hour).Format(time.RFC3339)}, false},
+		{"Developer - last page", &DeveloperPlan, parameters{"page": 5}, false},
+		{"Developer - past last page", &DeveloperPlan, parameters{"page": 6}, true},
+		{"Developer - page size past cap", &DeveloperPlan, parameters{"page": 2, "pageSize": 60}, true},
+		{"Business - old from", &BusinessPlan, parameters{"from": old, "page": 50}, false},
+		{"Custom - page past cap", &custom, parameters{"page": 3}, true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New("TestAPIKey")
+			if v.plan != nil {
+				WithPlan(*v.plan)(c)
+			}
+			err := c.checkPlan(v.parameters)
+			if (err != nil) != v.expectedError {
+				t.Fatalf("Expected error %v got '%v'", v.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestQuotaLimiter(t *testing.T) {
+	now := time.Date(2018, 7, 27, 12, 0, 0, 0, time.UTC)
+	l := &quotaLimiter{quota: 2, period: time.Hour, now: func() time.Time { return now }}
+
+	for i := 0; i < 2; i++ {
+		if err := l.take(); err != nil {
+			t.Fatalf("Unexpected error - %v", err)
+		}
+	}
+	if err := l.take(); err != ErrQuotaExceeded {
+		t.Fatalf("Expected '%v' got '%v'", ErrQuotaExceeded, err)
+	}
+	now = now.Add(time.Hour)
+	if r := l.remaining(); r != 2 {
+		t.Fatalf("Expected quota to reset to 2 got %d", r)
+	}
+}
+
+func TestPlanLimitsRequests(t *testing.T) {
+	var hits int
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`{"status":"ok","totalResults":0,"articles":[]}`))
+	}))
+	defer testServer.Close()
+
+	c := New("TestAPIKey", WithPlan(Plan{Name: "Tiny", Quota: 1, QuotaPeriod: time.Hour}))
+	c.APIUrl = testServer.URL
+	if r, ok := c.QuotaRemaining(); !ok || r != 1 {
+		t.Fatalf("Expected 1 remaining got %d %v", r, ok)
+	}
+	if _, err := c.GetTopHeadlines(parameters{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetTopHeadlines(parameters{}); err != ErrQuotaExceeded {
+		t.Fatalf("Expected '%v' got '%v'", ErrQuotaExceeded, err)
+	}
+	if hits != 1 {
+		t.Fatalf("Expected 1 upstream request got %d", hits)
+	}
+	if _, ok := New("TestAPIKey").QuotaRemaining(); ok {
+		t.Fatal("Expected no quota without a plan")
+	}
+}
+
+func TestPlanDefaultQuotaPeriod(t *testing.T) {
+	c := New("TestAPIKey", WithPlan(Plan{Name: "No period", Quota: 1}))
+	if c.limiter.period != 24*time.Hour {
+		t.Fatalf("Expected a quota period of 24h got %v", c.limiter.period)
+	}
+	if err := c.limiter.take(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.limiter.take(); err != ErrQuotaExceeded {
+		t.Fatalf("Expected '%v' got '%v'", ErrQuotaExceeded, err)
+	}
+}
@@ -0,0 +1,135 @@
+//Package promnewsapi exports Prometheus metrics for calls made by a newsapi Client
+package promnewsapi
+
+import (
+	"strconv"
+
+	"github.com/Oliver-Fish/newsapi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "newsapi"
+
+//Collector is a prometheus.Collector reporting the usage of a newsapi Client
+type Collector struct {
+	client    *newsapi.Client
+	requests  *prometheus.CounterVec
+	errors    *prometheus.CounterVec
+	retries   *prometheus.CounterVec
+	cacheHits *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	keyBudget *prometheus.Desc
+	quota     *prometheus.Desc
+}
+
+//NewCollector creates a Collector and adds a hook to c that feeds it
+//Latency is bucketed using buckets, prometheus.DefBuckets is used when none are passed
+func NewCollector(c *newsapi.Client, buckets ...float64) *Collector {
+	col := newCollector(c, buckets)
+	c.AddHook(col.observe)
+	return col
+}
+
+func newCollector(c *newsapi.Client, buckets []float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	col := &Collector{
+		client: c,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Calls made to NewsAPI by endpoint and outcome.",
+		}, []string{"endpoint", "outcome"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Error codes returned by NewsAPI.",
+		}, []string{"endpoint", "code"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Requests retried with another key from the key pool.",
+		}, []string{"endpoint"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_hits_total",
+			Help:      "Calls answered from the client's cache without a request.",
+		}, []string{"endpoint"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of calls made to NewsAPI.",
+			Buckets:   buckets,
+		}, []string{"endpoint"}),
+		keyBudget: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "key_budget_remaining"),
+			"Requests left in the daily budget of each key in the key pool, labelled by position and redacted key.",
+			[]string{"index", "key"}, nil),
+		quota: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "quota_remaining"),
+			"Requests left in the plan quota.",
+			nil, nil),
+	}
+	return col
+}
+
+//Register creates a Collector for c and registers it with reg
+//The hook feeding the Collector is only added to c once registration succeeds
+func Register(reg prometheus.Registerer, c *newsapi.Client, buckets ...float64) (*Collector, error) {
+	col := newCollector(c, buckets)
+	if err := reg.Register(col); err != nil {
+		return nil, err
+	}
+	c.AddHook(col.observe)
+	return col, nil
+}
+
+//observe updates the metrics for a finished call
+func (col *Collector) observe(e newsapi.Event) {
+	endpoint := string(e.Endpoint)
+	outcome := "ok"
+	if e.Err != nil {
+		outcome = "error"
+	}
+	col.requests.WithLabelValues(endpoint, outcome).Inc()
+	if code := e.Code(); code != "" {
+		col.errors.WithLabelValues(endpoint, code).Inc()
+	}
+	if e.Response != nil && e.Response.Attempts > 1 {
+		col.retries.WithLabelValues(endpoint).Add(float64(e.Response.Attempts - 1))
+	}
+	if e.Response != nil && e.Response.FromCache {
+		col.cacheHits.WithLabelValues(endpoint).Inc()
+	}
+	col.duration.WithLabelValues(endpoint).Observe(e.Duration.Seconds())
+}
+
+//Describe implements prometheus.Collector
+func (col *Collector) Describe(ch chan<- *prometheus.Desc) {
+	col.requests.Describe(ch)
+	col.errors.Describe(ch)
+	col.retries.Describe(ch)
+	col.cacheHits.Describe(ch)
+	col.duration.Describe(ch)
+	ch <- col.keyBudget
+	ch <- col.quota
+}
+
+//Collect implements prometheus.Collector
+func (col *Collector) Collect(ch chan<- prometheus.Metric) {
+	col.requests.Collect(ch)
+	col.errors.Collect(ch)
+	col.retries.Collect(ch)
+	col.cacheHits.Collect(ch)
+	col.duration.Collect(ch)
+	for i, s := range col.client.KeyStats() {
+		if s.Remaining < 0 {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(col.keyBudget, prometheus.GaugeValue, float64(s.Remaining), strconv.Itoa(i), s.Key)
+	}
+	if r, ok := col.client.QuotaRemaining(); ok {
+		ch <- prometheus.MustNewConstMetric(col.quota, prometheus.GaugeValue, float64(r))
+	}
+}
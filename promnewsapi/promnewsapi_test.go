@@ -0,0 +1,151 @@
+package promnewsapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oliver-Fish/newsapi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func fakeServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") == "exhausted-key" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","code":"apiKeyExhausted","message":"Your API key has no more requests available."}`))
+			return
+		}
+		if r.URL.Query().Get("q") == "missing" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","code":"parametersMissing","message":"Required parameters are missing."}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ok","totalResults":0,"articles":[]}`))
+	}))
+}
+
+func TestRegister(t *testing.T) {
+	testServer := fakeServer()
+	defer testServer.Close()
+
+	pool := newsapi.NewKeyPool(newsapi.RoundRobin)
+	pool.Add("exhausted-key", 10)
+	pool.Add("valid-key-1234", 100)
+	c := newsapi.New("", newsapi.WithKeyPool(pool), newsapi.WithPlan(newsapi.Plan{Name: "Test", Quota: 50, QuotaPeriod: time.Hour}))
+	c.APIUrl = testServer.URL
+
+	reg := prometheus.NewPedanticRegistry()
+	col, err := Register(reg, c, 0.5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Register(reg, c); err == nil {
+		t.Fatal("Expected error registering twice got nil")
+	}
+
+	c.GetEverything(map[string]interface{}{"q": "test"})
+	c.GetEverything(map[string]interface{}{"q": "missing"})
+	c.GetSources(map[string]interface{}{})
+
+	tt := []struct {
+		testName string
+		metric   string
+		expected string
+	}{
+		{"Requests", "newsapi_requests_total", `
+# HELP newsapi_requests_total Calls made to NewsAPI by endpoint and outcome.
+# TYPE newsapi_requests_total counter
+newsapi_requests_total{endpoint="everything",outcome="error"} 1
+newsapi_requests_total{endpoint="everything",outcome="ok"} 1
+newsapi_requests_total{endpoint="sources",outcome="ok"} 1
+`},
+		{"Errors", "newsapi_errors_total", `
+# HELP newsapi_errors_total Error codes returned by NewsAPI.
+# TYPE newsapi_errors_total counter
+newsapi_errors_total{code="parametersMissing",endpoint="everything"} 1
+`},
+		{"Retries", "newsapi_retries_total", `
+# HELP newsapi_retries_total Requests retried with another key from the key pool.
+# TYPE newsapi_retries_total counter
+newsapi_retries_total{endpoint="everything"} 1
+`},
+		{"Key budget", "newsapi_key_budget_remaining", `
+# HELP newsapi_key_budget_remaining Requests left in the daily budget of each key in the key pool, labelled by position and redacted key.
+# TYPE newsapi_key_budget_remaining gauge
+newsapi_key_budget_remaining{index="0",key="****-key"} 9
+newsapi_key_budget_remaining{index="1",key="****1234"} 97
+`},
+		{"Quota", "newsapi_quota_remaining", `
+# HELP newsapi_quota_remaining Requests left in the plan quota.
+# TYPE newsapi_quota_remaining gauge
+newsapi_quota_remaining 46
+`},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			if err := testutil.CollectAndCompare(col, strings.NewReader(v.expected), v.metric); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	if n := testutil.CollectAndCount(col, "newsapi_request_duration_seconds"); n != 2 {
+		t.Fatalf("Expected 2 duration series got %d", n)
+	}
+	if problems, err := testutil.GatherAndLint(reg); err != nil || len(problems) != 0 {
+		t.Fatalf("Lint failed %v %+v", err, problems)
+	}
+}
+
+func TestCollectorKeysExhausted(t *testing.T) {
+	testServer := fakeServer()
+	defer testServer.Close()
+
+	c := newsapi.New("", newsapi.WithKeyPool(newsapi.NewKeyPool(newsapi.RoundRobin, "exhausted-key", "exhausted-key")))
+	c.APIUrl = testServer.URL
+	col := NewCollector(c)
+	if _, err := c.GetEverything(map[string]interface{}{"q": "test"}); err != newsapi.ErrNoKeysAvailable {
+		t.Fatalf("Expected '%v' got '%v'", newsapi.ErrNoKeysAvailable, err)
+	}
+
+	expected := `
+# HELP newsapi_retries_total Requests retried with another key from the key pool.
+# TYPE newsapi_retries_total counter
+newsapi_retries_total{endpoint="everything"} 1
+`
+	if err := testutil.CollectAndCompare(col, strings.NewReader(expected), "newsapi_retries_total"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectorWithoutPoolOrPlan(t *testing.T) {
+	c := newsapi.New("TestAPIKey")
+	col := NewCollector(c)
+	if n := testutil.CollectAndCount(col, "newsapi_key_budget_remaining", "newsapi_quota_remaining"); n != 0 {
+		t.Fatalf("Expected no budget metrics got %d", n)
+	}
+}
+
+func TestCollectorCacheHits(t *testing.T) {
+	testServer := fakeServer()
+	defer testServer.Close()
+
+	c := newsapi.New("TestAPIKey", newsapi.WithCache(time.Minute))
+	c.APIUrl = testServer.URL
+	col := NewCollector(c)
+	c.GetEverything(map[string]interface{}{"q": "test"})
+	c.GetEverything(map[string]interface{}{"q": "test"})
+
+	expected := `
+# HELP newsapi_cache_hits_total Calls answered from the client's cache without a request.
+# TYPE newsapi_cache_hits_total counter
+newsapi_cache_hits_total{endpoint="everything"} 1
+`
+	if err := testutil.CollectAndCompare(col, strings.NewReader(expected), "newsapi_cache_hits_total"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,186 @@
+package newsapi
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+//maxQueryLength is the longest q parameter NewsAPI accepts
+const maxQueryLength = 500
+
+//QueryError describes a syntax error in a q parameter
+type QueryError struct {
+	Query   string
+	Offset  int //Character in Query the error was found at, counting from zero
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("Invalid query at character %d: %v", e.Offset+1, e.Message)
+}
+
+//queryToken is a word, phrase, operator or bracket in a query
+type queryToken struct {
+	kind   byte //'w' for a word, '"' for a phrase, '&' for AND, OR or NOT, or the bracket
+	text   string
+	offset int
+}
+
+//WithQueryValidation makes the Client check q with ValidateQuery before sending a request
+//NewsAPI is more forgiving than ValidateQuery, so queries it would accept may be rejected
+func WithQueryValidation() option {
+	return func(c *Client) {
+		c.checkQuery = true
+	}
+}
+
+//ValidateQuery checks q follows the NewsAPI search syntax
+//Phrases are quoted, words and phrases can be prefixed with + to require them or - to exclude them
+//and can be combined with AND, OR, NOT and brackets
+//
+//	crypto AND (ethereum OR litecoin) NOT bitcoin
+func ValidateQuery(q string) error {
+	if strings.TrimSpace(q) == "" {
+		return fmt.Errorf("Expected query got empty string")
+	}
+	if n := utf8.RuneCountInString(q); n > maxQueryLength {
+		return &QueryError{q, maxQueryLength, fmt.Sprintf("Query is %d characters, the maximum is %d", n, maxQueryLength)}
+	}
+	tokens, err := tokenizeQuery(q)
+	if err != nil {
+		return err
+	}
+	p := queryParser{q: q, tokens: tokens}
+	if err := p.expression(); err != nil {
+		return err
+	}
+	if p.pos < len(tokens) {
+		return p.fail("Unexpected )")
+	}
+	return nil
+}
+
+//tokenizeQuery splits q into tokens, offsets count characters rather than bytes
+func tokenizeQuery(q string) ([]queryToken, error) {
+	r := []rune(q)
+	var tokens []queryToken
+	for i := 0; i < len(r); {
+		switch c := r[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, queryToken{byte(c), string(c), i})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(r) && r[end] != '"' {
+				end++
+			}
+			if end == len(r) {
+				return nil, &QueryError{q, i, "Unterminated phrase, expected a closing \""}
+			}
+			if strings.TrimSpace(string(r[i+1:end])) == "" {
+				return nil, &QueryError{q, i, "Empty phrase"}
+			}
+			tokens = append(tokens, queryToken{'"', string(r[i : end+1]), i})
+			i = end + 1
+		default:
+			end := i
+			for end < len(r) && !strings.ContainsRune(" \t\n()\"", r[end]) {
+				end++
+			}
+			//A prefix can be followed by a phrase or brackets, for example -"climate change"
+			if end == i+1 && (c == '+' || c == '-') && end < len(r) && (r[end] == '"' || r[end] == '(') {
+				tokens = append(tokens, queryToken{byte(c), string(c), i})
+				i = end
+				continue
+			}
+			t := queryToken{'w', string(r[i:end]), i}
+			switch t.text {
+			case "AND", "OR", "NOT":
+				t.kind = '&'
+			}
+			tokens = append(tokens, t)
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+//queryParser checks the tokens of a query form a valid expression
+type queryParser struct {
+	q      string
+	tokens []queryToken
+	pos    int
+}
+
+//expression parses terms joined by operators, or simply next to each other, until the end or a closing bracket
+func (p *queryParser) expression() error {
+	if err := p.term(); err != nil {
+		return err
+	}
+	for p.pos < len(p.tokens) && p.tokens[p.pos].kind != ')' {
+		if p.tokens[p.pos].kind == '&' {
+			op := p.tokens[p.pos]
+			p.pos++
+			if p.pos == len(p.tokens) || p.tokens[p.pos].kind == ')' {
+				return &QueryError{p.q, op.offset, fmt.Sprintf("Expected a word, phrase or ( after %v", op.text)}
+			}
+		}
+		if err := p.term(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//term parses an optionally prefixed word, phrase or bracketed expression
+func (p *queryParser) term() error {
+	if p.pos == len(p.tokens) {
+		return p.fail("Expected a word, phrase or (")
+	}
+	t := p.tokens[p.pos]
+	switch t.kind {
+	case 'w':
+		if w := strings.TrimLeft(t.text, "+-"); w == "" {
+			return p.fail(fmt.Sprintf("Expected a word after %v", t.text))
+		} else if len(t.text)-len(w) > 1 {
+			return p.fail(fmt.Sprintf("Only one + or - is allowed before %v", w))
+		}
+		p.pos++
+		return nil
+	case '"':
+		p.pos++
+		return nil
+	case '+', '-':
+		//tokenizeQuery only splits off a prefix before a phrase or (
+		p.pos++
+		return p.term()
+	case '(':
+		p.pos++
+		if p.pos < len(p.tokens) && p.tokens[p.pos].kind == ')' {
+			return &QueryError{p.q, t.offset, "Empty brackets"}
+		}
+		if err := p.expression(); err != nil {
+			return err
+		}
+		if p.pos == len(p.tokens) {
+			return &QueryError{p.q, t.offset, "Unclosed (, expected a matching )"}
+		}
+		p.pos++
+		return nil
+	case '&':
+		return p.fail(fmt.Sprintf("Expected a word, phrase or ( before %v", t.text))
+	}
+	return p.fail("Unexpected )")
+}
+
+//fail returns a QueryError at the current token, or the end of the query when there are no more tokens
+func (p *queryParser) fail(msg string) error {
+	offset := utf8.RuneCountInString(p.q)
+	if p.pos < len(p.tokens) {
+		offset = p.tokens[p.pos].offset
+	}
+	return &QueryError{p.q, offset, msg}
+}
@@ -0,0 +1,103 @@
+package newsapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValidateQuery(t *testing.T) {
+	tt := []struct {
+		testName string
+		q        string
+		offset   int
+		message  string
+	}{
+		{"Word", "bitcoin", -1, ""},
+		{"Words", "bitcoin ethereum", -1, ""},
+		{"Phrase", `"climate change"`, -1, ""},
+		{"Prefixes", `+bitcoin -ethereum -"proof of stake"`, -1, ""},
+		{"Operators", "crypto AND (ethereum OR litecoin) NOT bitcoin", -1, ""},
+		{"Nested brackets", `(a OR (b AND "c d")) -(e OR f)`, -1, ""},
+		{"Hyphenated word", "co-op", -1, ""},
+		{"Lower case operators are words", "and or not", -1, ""},
+		{"Unicode", `"café" AND crème`, -1, ""},
+		{"Empty", "  ", -1, "Expected query got empty string"},
+		{"Unterminated phrase", `bitcoin "price`, 8, "Unterminated phrase"},
+		{"Empty phrase", `a "  "`, 2, "Empty phrase"},
+		{"Leading operator", "AND bitcoin", 0, "Expected a word, phrase or ( before AND"},
+		{"Trailing operator", "bitcoin OR", 8, "Expected a word, phrase or ( after OR"},
+		{"Double operator", "a AND OR b", 6, "Expected a word, phrase or ( before OR"},
+		{"Operator before bracket", "(a OR) b", 3, "Expected a word, phrase or ( after OR"},
+		{"Unclosed bracket", "(a OR b", 0, "Unclosed ("},
+		{"Unexpected bracket", "a OR b)", 6, "Unexpected )"},
+		{"Leading bracket", ")", 0, "Unexpected )"},
+		{"Empty brackets", "a ()", 2, "Empty brackets"},
+		{"Lone prefix", "a - b", 2, "Expected a word after -"},
+		{"Double prefix", "+-a", 0, "Only one + or - is allowed before a"},
+		{"Unicode offset", `café "x`, 5, "Unterminated phrase"},
+		{"Too long", strings.Repeat("a", 501), 500, "Query is 501 characters, the maximum is 500"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			err := ValidateQuery(v.q)
+			if v.message == "" {
+				if err != nil {
+					t.Fatalf("Expected no error got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), v.message) {
+				t.Fatalf("Expected error containing '%v' got %v", v.message, err)
+			}
+			qe, ok := err.(*QueryError)
+			if v.offset < 0 {
+				if ok {
+					t.Fatalf("Expected a plain error got QueryError %v", err)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("Expected QueryError got %T", err)
+			}
+			if qe.Offset != v.offset || qe.Query != v.q {
+				t.Fatalf("Expected offset %v got %v", v.offset, qe.Offset)
+			}
+		})
+	}
+}
+
+func TestWithQueryValidation(t *testing.T) {
+	tt := []struct {
+		testName string
+		q        string
+		validate bool
+		err      string
+	}{
+		{"Lone minus", "rock - paper", false, ""},
+		{"Leading NOT", "NOT bitcoin", false, ""},
+		{"Unbalanced bracket", "smile :)", false, ""},
+		{"Empty", "", false, "Expected query got empty string"},
+		{"Validated", "bitcoin AND price", true, ""},
+		{"Validated syntax error", "bitcoin AND", true, "Invalid query at character 9"},
+		{"Validated leading NOT", "NOT bitcoin", true, "Expected a word, phrase or ( before NOT"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New("abc")
+			if v.validate {
+				c = New("abc", WithQueryValidation())
+			}
+			_, err := c.BuildRequest(context.Background(), Request{EndpointEverything, map[string]interface{}{"q": v.q}})
+			if v.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), v.err) {
+				t.Fatalf("Expected error containing '%v' got %v", v.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package newsapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+//Request describes a call to a NewsAPI endpoint
+type Request struct {
+	Endpoint   Endpoint
+	Parameters map[string]interface{} //Same parameters and types as the matching Get method
+}
+
+//DryRunError is returned in place of sending a request when the Client was created WithDryRun
+type DryRunError struct {
+	Method string
+	URL    string      //Any API key is redacted
+	Header http.Header //Any API key is redacted
+}
+
+func (e *DryRunError) Error() string {
+	return "Dry run " + e.Method + " " + e.URL
+}
+
+//WithDryRun validates and builds each request but returns a DryRunError instead of sending it
+//No quota is used and hooks aren't called
+func WithDryRun() option {
+	return func(c *Client) {
+		c.dryRun = true
+	}
+}
+
+//BuildRequest validates r and returns the request the Client would send for it, including the API key
+//Nothing is sent, with a key pool the key that would be used next is set but not counted against its budget
+func (c *Client) BuildRequest(ctx context.Context, r Request) (*http.Request, error) {
+	u, err := c.requestURL(r.Endpoint, r.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	return c.newRequest(ctx, u, c.nextKey())
+}
+
+//nextKey returns the key the next request would be sent with
+func (c *Client) nextKey() Secret {
+	if c.keyPool == nil {
+		return c.APIKey
+	}
+	key, err := c.keyPool.peek()
+	if err != nil {
+		return ""
+	}
+	return key
+}
+
+//requestURL adds the Client's default parameters to p, validates it against the Client's plan and the parameters e accepts and returns the URL to request
+func (c *Client) requestURL(e Endpoint, p parameters) (string, error) {
+	if c.configErr != nil {
+		return "", c.configErr
+	}
+	ap, err := endpointParameters(e)
+	if err != nil {
+		return "", err
+	}
+	p = c.withDefaults(e, p, ap)
+	if q, ok := p["q"]; ok && c.checkQuery {
+		if err := ValidateQuery(fmt.Sprintf("%v", q)); err != nil {
+			return "", err
+		}
+	}
+	if e != EndpointSources {
+		if err := c.checkPlan(p); err != nil {
+			return "", err
+		}
+	}
+	return p.buildURL(c.endpointURL(e), ap)
+}
+
+//endpointParameters returns the parameters e accepts
+func endpointParameters(e Endpoint) (*allowedParameters, error) {
+	switch e {
+	case EndpointTopHeadlines:
+		return &topHeadlinesParameters, nil
+	case EndpointEverything:
+		return &everythingParameters, nil
+	case EndpointSources:
+		return &sourcesParameters, nil
+	}
+	return nil, fmt.Errorf("Unknown endpoint %v", e)
+}
+
+//newRequest creates a request for endpoint with key placed according to the Client's AuthMethod
+func (c *Client) newRequest(ctx context.Context, endpoint string, key Secret) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	if key == "" {
+		return nil, errors.New("Expected API key got nothing")
+	}
+	c.authorize(req, key)
+	return req, nil
+}
+
+//dryRunError builds the request for endpoint and describes it in a DryRunError
+func (c *Client) dryRunError(endpoint string) error {
+	req, err := c.newRequest(context.Background(), endpoint, c.nextKey())
+	if err != nil {
+		return err
+	}
+	h := req.Header.Clone()
+	for _, k := range []string{"X-Api-Key", "Authorization"} {
+		if h.Get(k) != "" {
+			h.Set(k, redacted)
+		}
+	}
+	return &DryRunError{Method: req.Method, URL: redactURL(req.URL.String()), Header: h}
+}
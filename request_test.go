@@ -0,0 +1,127 @@
+package newsapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildRequest(t *testing.T) {
+	tt := []struct {
+		testName string
+		options  []option
+		request  Request
+		expected string
+		header   string
+		value    string
+		err      bool
+	}{
+		{"Top headlines", nil, Request{EndpointTopHeadlines, map[string]interface{}{"country": "gb", "q": "test"}}, "https://newsapi.org/v2/top-headlines?country=gb&q=test", "X-Api-Key", "abc", false},
+		{"Everything", nil, Request{EndpointEverything, map[string]interface{}{"q": "test", "page": 2}}, "https://newsapi.org/v2/everything?page=2&q=test", "X-Api-Key", "abc", false},
+		{"Sources", nil, Request{EndpointSources, nil}, "https://newsapi.org/v2/sources?", "X-Api-Key", "abc", false},
+		{"Everything language", nil, Request{EndpointEverything, map[string]interface{}{"q": "test", "language": "de"}}, "https://newsapi.org/v2/everything?language=de&q=test", "X-Api-Key", "abc", false},
+		{"Sources language", nil, Request{EndpointSources, map[string]interface{}{"language": "en"}}, "https://newsapi.org/v2/sources?language=en", "X-Api-Key", "abc", false},
+		{"Query auth", []option{WithAuth(AuthQuery)}, Request{EndpointSources, nil}, "https://newsapi.org/v2/sources?apiKey=abc", "", "", false},
+		{"Bearer auth", []option{WithAuth(AuthBearer)}, Request{EndpointSources, nil}, "https://newsapi.org/v2/sources?", "Authorization", "Bearer abc", false},
+		{"Key pool", []option{WithKeyPool(NewKeyPool(RoundRobin, "pooled"))}, Request{EndpointSources, nil}, "https://newsapi.org/v2/sources?", "X-Api-Key", "pooled", false},
+		{"Invalid parameter", nil, Request{EndpointSources, map[string]interface{}{"q": "test"}}, "", "", "", true},
+		{"Invalid country", nil, Request{EndpointTopHeadlines, map[string]interface{}{"country": "xx"}}, "", "", "", true},
+		{"Plan limit", []option{WithPlan(DeveloperPlan)}, Request{EndpointEverything, map[string]interface{}{"page": 10}}, "", "", "", true},
+		{"Unknown endpoint", nil, Request{Endpoint("other"), nil}, "", "", "", true},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New("abc", v.options...)
+			req, err := c.BuildRequest(context.Background(), v.request)
+			if v.err {
+				if err == nil {
+					t.Fatal("Expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.Method != "GET" || req.URL.String() != v.expected {
+				t.Fatalf("Expected GET %v got %v %v", v.expected, req.Method, req.URL)
+			}
+			if v.header != "" && req.Header.Get(v.header) != v.value {
+				t.Fatalf("Expected header %v to be '%v' got '%v'", v.header, v.value, req.Header.Get(v.header))
+			}
+		})
+	}
+}
+
+func TestBuildRequestKeyPoolUnchanged(t *testing.T) {
+	p := NewKeyPool(RoundRobin, "one", "two")
+	c := New("", WithKeyPool(p))
+	for i := 0; i < 3; i++ {
+		req, err := c.BuildRequest(context.Background(), Request{Endpoint: EndpointSources})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.Header.Get("X-Api-Key") != "one" {
+			t.Fatalf("Expected key one got %v", req.Header.Get("X-Api-Key"))
+		}
+	}
+	for _, s := range p.Stats() {
+		if s.Requests != 0 {
+			t.Fatalf("Expected no requests counted got %v", s.Requests)
+		}
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	var calls int
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer testServer.Close()
+
+	var events int
+	c := New(testSecret, WithDryRun(), WithAuth(AuthQuery), WithBaseURL(testServer.URL), WithPlan(DeveloperPlan), WithHook(func(Event) { events++ }))
+
+	_, err := c.GetEverything(parameters{"q": "test"})
+	dr, ok := err.(*DryRunError)
+	if !ok {
+		t.Fatalf("Expected DryRunError got %v", err)
+	}
+	if dr.Method != "GET" || dr.URL != testServer.URL+"/everything?apiKey=REDACTED&q=test" {
+		t.Fatalf("Unexpected dry run %v %v", dr.Method, dr.URL)
+	}
+	if strings.Contains(err.Error(), testSecret) {
+		t.Fatalf("Key leaked in '%v'", err)
+	}
+
+	_, err = c.StreamTopHeadlines(parameters{"q": "test"}, func(Article) error { return nil })
+	if _, ok := err.(*DryRunError); !ok {
+		t.Fatalf("Expected DryRunError got %v", err)
+	}
+
+	_, err = c.GetEverything(parameters{"from": time.Now().AddDate(-1, 0, 0).Format("2006-01-02")})
+	if _, ok := err.(*DryRunError); ok || err == nil {
+		t.Fatalf("Expected validation error got %v", err)
+	}
+
+	if calls != 0 || events != 0 {
+		t.Fatalf("Expected nothing sent got %v requests and %v events", calls, events)
+	}
+	if n, _ := c.QuotaRemaining(); n != DeveloperPlan.Quota {
+		t.Fatalf("Expected no quota used got %v remaining", n)
+	}
+}
+
+func TestDryRunHeaders(t *testing.T) {
+	c := New(testSecret, WithDryRun(), WithAuth(AuthBearer))
+	_, err := c.GetSources(parameters{})
+	dr, ok := err.(*DryRunError)
+	if !ok {
+		t.Fatalf("Expected DryRunError got %v", err)
+	}
+	if dr.Header.Get("Authorization") != "REDACTED" {
+		t.Fatalf("Expected Authorization header to be redacted got '%v'", dr.Header.Get("Authorization"))
+	}
+}
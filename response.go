@@ -0,0 +1,30 @@
+package newsapi
+
+import (
+	"net/http"
+	"time"
+)
+
+//Response contains metadata about the reply NewsAPI sent for a request
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Latency    time.Duration //Time from sending the request to reading the whole body
+	URL        string        //Final URL of the request after any redirects
+	Shared     bool          //True when the reply was shared from another caller's in-flight request
+	FromCache  bool          //True when the reply was served from the Client's cache without a request
+	Attempts   int           //Number of keys tried when using a key pool
+}
+
+func newResponse(res *http.Response, latency time.Duration) *Response {
+	r := &Response{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Latency:    latency,
+		Attempts:   1,
+	}
+	if res.Request != nil {
+		r.URL = redactURL(res.Request.URL.String())
+	}
+	return r
+}
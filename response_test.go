@@ -0,0 +1,91 @@
+package newsapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseMetadata(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redirect/top-headlines":
+			http.Redirect(w, r, "/top-headlines?"+r.URL.RawQuery, http.StatusFound)
+		case r.URL.Query().Get("q") == "fail":
+			w.Header().Set("X-Request-Id", "failed")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","code":"parameterInvalid","message":"Invalid parameter"}`))
+		default:
+			w.Header().Set("X-Request-Id", "abc")
+			w.Write([]byte(`{"status":"ok","totalResults":0,"articles":[]}`))
+		}
+	}))
+	defer testServer.Close()
+
+	tt := []struct {
+		testName      string
+		base          string
+		parameters    parameters
+		expectedCode  int
+		expectedURL   string
+		expectedID    string
+		expectedError bool
+	}{
+		{"Success", testServer.URL, parameters{"q": "ok"}, http.StatusOK, testServer.URL + "/top-headlines?q=ok", "abc", false},
+		{"Redirect", testServer.URL + "/redirect", parameters{"q": "ok"}, http.StatusOK, testServer.URL + "/top-headlines?q=ok", "abc", false},
+		{"API error", testServer.URL, parameters{"q": "fail"}, http.StatusBadRequest, testServer.URL + "/top-headlines?q=fail", "failed", true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New("TestAPIKey")
+			c.APIUrl = v.base
+			a, err := c.GetTopHeadlines(v.parameters)
+			if (err != nil) != v.expectedError {
+				t.Fatalf("Unexpected error value %v", err)
+			}
+			r := a.Response
+			if r == nil {
+				t.Fatal("Expected response metadata got nil")
+			}
+			if r.StatusCode != v.expectedCode {
+				t.Fatalf("Expected status %d got %d", v.expectedCode, r.StatusCode)
+			}
+			if r.URL != v.expectedURL {
+				t.Fatalf("Expected URL '%v' got '%v'", v.expectedURL, r.URL)
+			}
+			if r.Header.Get("X-Request-Id") != v.expectedID {
+				t.Fatalf("Expected request id '%v' got '%v'", v.expectedID, r.Header.Get("X-Request-Id"))
+			}
+			if r.Latency <= 0 || r.Attempts != 1 || r.Shared {
+				t.Fatalf("Unexpected metadata %+v", r)
+			}
+		})
+	}
+}
+
+func TestResponseMetadataNoReply(t *testing.T) {
+	c := New("")
+	a, err := c.GetSources(parameters{})
+	if err == nil {
+		t.Fatal("Expected error got nil")
+	}
+	if a.Response != nil {
+		t.Fatalf("Expected no response metadata got %+v", a.Response)
+	}
+}
+
+func TestResponseMetadataKeyPool(t *testing.T) {
+	testServer := keyServer()
+	defer testServer.Close()
+
+	c := New("", WithKeyPool(NewKeyPool(RoundRobin, "exhausted-key", "valid-key")))
+	c.APIUrl = testServer.URL
+	a, err := c.GetEverything(parameters{"q": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Response.Attempts != 2 {
+		t.Fatalf("Expected 2 attempts got %d", a.Response.Attempts)
+	}
+}
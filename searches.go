@@ -0,0 +1,227 @@
+package newsapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+)
+
+//SavedSearch is a named request kept in a saved search file
+type SavedSearch struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Endpoint    Endpoint               `json:"endpoint"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+//SavedSearches is the contents of a saved search file
+//
+//	{"searches": [{"name": "uk-tech", "endpoint": "top-headlines", "parameters": {"country": "gb", "category": "technology"}}]}
+type SavedSearches struct {
+	Searches []SavedSearch `json:"searches"`
+}
+
+//SearchResults holds the results of running a SavedSearch, only the field for its endpoint is set
+type SearchResults struct {
+	Articles *ArticleResults
+	Sources  *SourceResults
+}
+
+//LoadSearches reads and validates the saved search file at path
+func LoadSearches(path string) (*SavedSearches, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := ReadSearches(f)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid saved search file %v: %v", path, err)
+	}
+	return s, nil
+}
+
+//ReadSearches reads and validates saved searches from r
+func ReadSearches(r io.Reader) (*SavedSearches, error) {
+	var s SavedSearches
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, s.Validate()
+}
+
+//Save writes the saved searches to path as indented JSON
+func (s *SavedSearches) Save(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+//Validate checks each search has a unique name and a valid request
+func (s *SavedSearches) Validate() error {
+	names := make(map[string]struct{})
+	for _, ss := range s.Searches {
+		if _, ok := names[ss.Name]; ok {
+			return fmt.Errorf("Duplicate saved search %v", ss.Name)
+		}
+		names[ss.Name] = struct{}{}
+		if err := ss.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Get returns the search called name
+func (s *SavedSearches) Get(name string) (SavedSearch, bool) {
+	for _, ss := range s.Searches {
+		if ss.Name == name {
+			return ss, true
+		}
+	}
+	return SavedSearch{}, false
+}
+
+//Tagged returns the searches with tag, or every search when tag is empty
+func (s *SavedSearches) Tagged(tag string) []SavedSearch {
+	var l []SavedSearch
+	for _, ss := range s.Searches {
+		if tag == "" {
+			l = append(l, ss)
+			continue
+		}
+		for _, t := range ss.Tags {
+			if t == tag {
+				l = append(l, ss)
+				break
+			}
+		}
+	}
+	return l
+}
+
+//Add validates ss and adds it, replacing any search with the same name when replace is true
+func (s *SavedSearches) Add(ss SavedSearch, replace bool) error {
+	if err := ss.Validate(); err != nil {
+		return err
+	}
+	for i := range s.Searches {
+		if s.Searches[i].Name != ss.Name {
+			continue
+		}
+		if !replace {
+			return fmt.Errorf("Saved search %v already exists", ss.Name)
+		}
+		s.Searches[i] = ss
+		return nil
+	}
+	s.Searches = append(s.Searches, ss)
+	return nil
+}
+
+//Run runs the search called name with c
+func (s *SavedSearches) Run(c *Client, name string) (SearchResults, error) {
+	ss, ok := s.Get(name)
+	if !ok {
+		return SearchResults{}, fmt.Errorf("No saved search called %v", name)
+	}
+	return c.RunSearch(ss)
+}
+
+//Validate checks the search has a name and that its parameters are valid for its endpoint
+func (ss SavedSearch) Validate() error {
+	if strings.TrimSpace(ss.Name) == "" {
+		return fmt.Errorf("Expected saved search name got nothing")
+	}
+	if _, err := ss.Request(); err != nil {
+		return fmt.Errorf("Saved search %v: %v", ss.Name, err)
+	}
+	return nil
+}
+
+//Request converts the search into a Request with its parameters in the types the endpoint expects
+//Numbers and lists decoded from JSON are converted to int and []string
+func (ss SavedSearch) Request() (Request, error) {
+	ap, err := endpointParameters(ss.Endpoint)
+	if err != nil {
+		return Request{}, err
+	}
+	p := make(parameters, len(ss.Parameters))
+	for k, v := range ss.Parameters {
+		t, ok := (*ap)[k]
+		if !ok {
+			return Request{}, fmt.Errorf("Invalid parameter %v", k)
+		}
+		if p[k], err = convertParameter(k, t, v); err != nil {
+			return Request{}, err
+		}
+	}
+	if _, err := p.buildURL("", ap); err != nil {
+		return Request{}, err
+	}
+	return Request{Endpoint: ss.Endpoint, Parameters: p}, nil
+}
+
+//convertParameter converts v to the type t named in allowedParameters
+func convertParameter(k, t string, v interface{}) (interface{}, error) {
+	switch t {
+	case "int":
+		switch n := v.(type) {
+		case int:
+			return n, nil
+		case float64:
+			if n == math.Trunc(n) {
+				return int(n), nil
+			}
+		}
+	case "[]string":
+		switch l := v.(type) {
+		case []string:
+			return l, nil
+		case string:
+			return strings.Split(l, ","), nil
+		case []interface{}:
+			s := make([]string, len(l))
+			for i, e := range l {
+				str, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("Invalid type for parameter %v expected a list of strings", k)
+				}
+				s[i] = str
+			}
+			return s, nil
+		}
+	case "string":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("Invalid type for parameter %v expected type %v", k, t)
+}
+
+//RunSearch runs ss with the Client
+func (c *Client) RunSearch(ss SavedSearch) (SearchResults, error) {
+	r, err := ss.Request()
+	if err != nil {
+		return SearchResults{}, err
+	}
+	switch ss.Endpoint {
+	case EndpointTopHeadlines:
+		o, err := c.GetTopHeadlines(r.Parameters)
+		return SearchResults{Articles: &o}, err
+	case EndpointEverything:
+		o, err := c.GetEverything(r.Parameters)
+		return SearchResults{Articles: &o}, err
+	}
+	o, err := c.GetSources(r.Parameters)
+	return SearchResults{Sources: &o}, err
+}
@@ -0,0 +1,144 @@
+package newsapi
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSearches = `{"searches": [
+	{"name": "uk-tech", "description": "UK technology headlines", "tags": ["uk", "tech"], "endpoint": "top-headlines", "parameters": {"country": "gb", "category": "technology", "pageSize": 50}},
+	{"name": "rust", "tags": ["tech"], "endpoint": "everything", "parameters": {"q": "rust", "domains": ["bbc.co.uk", "theguardian.com"], "language": "en"}},
+	{"name": "english-sources", "endpoint": "sources", "parameters": {"language": "en"}}
+]}`
+
+func TestReadSearches(t *testing.T) {
+	tt := []struct {
+		testName string
+		data     string
+		err      string
+	}{
+		{"Valid", testSearches, ""},
+		{"Empty", `{"searches": []}`, ""},
+		{"Duplicate name", `{"searches": [{"name": "a", "endpoint": "sources"}, {"name": "a", "endpoint": "sources"}]}`, "Duplicate saved search a"},
+		{"Missing name", `{"searches": [{"endpoint": "sources"}]}`, "Expected saved search name got nothing"},
+		{"Unknown endpoint", `{"searches": [{"name": "a", "endpoint": "other"}]}`, "Unknown endpoint other"},
+		{"Invalid parameter", `{"searches": [{"name": "a", "endpoint": "sources", "parameters": {"q": "test"}}]}`, "Invalid parameter q"},
+		{"Invalid value", `{"searches": [{"name": "a", "endpoint": "sources", "parameters": {"country": "xx"}}]}`, "Unsupported country code xx"},
+		{"Fractional int", `{"searches": [{"name": "a", "endpoint": "everything", "parameters": {"page": 1.5}}]}`, "Invalid type for parameter page expected type int"},
+		{"Mixed list", `{"searches": [{"name": "a", "endpoint": "everything", "parameters": {"domains": ["a", 1]}}]}`, "expected a list of strings"},
+		{"Unknown field", `{"searches": [{"name": "a", "endpoint": "sources", "query": "x"}]}`, "unknown field"},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			_, err := ReadSearches(strings.NewReader(v.data))
+			if v.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if v.err != "" && (err == nil || !strings.Contains(err.Error(), v.err)) {
+				t.Fatalf("Expected error containing '%v' got %v", v.err, err)
+			}
+		})
+	}
+}
+
+func TestSavedSearchRequest(t *testing.T) {
+	s, err := ReadSearches(strings.NewReader(testSearches))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss, ok := s.Get("rust")
+	if !ok {
+		t.Fatal("Expected to find saved search rust")
+	}
+	r, err := ss.Request()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := New("abc").BuildRequest(context.Background(), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.String() != "https://newsapi.org/v2/everything?domains=bbc.co.uk%2Ctheguardian.com&language=en&q=rust" {
+		t.Fatalf("Unexpected URL %v", req.URL)
+	}
+	if n := len(s.Tagged("tech")); n != 2 {
+		t.Fatalf("Expected 2 searches tagged tech got %v", n)
+	}
+	if n := len(s.Tagged("")); n != 3 {
+		t.Fatalf("Expected 3 searches got %v", n)
+	}
+}
+
+func TestRunSearch(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := map[string]string{
+			"/top-headlines": "testdata/topheadlines_sucess.json",
+			"/sources":       "testdata/sources_sucess.json",
+		}[r.URL.Path]
+		d, _ := ioutil.ReadFile(f)
+		w.Write(d)
+	}))
+	defer testServer.Close()
+
+	s, err := ReadSearches(strings.NewReader(testSearches))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New("abc", WithBaseURL(testServer.URL))
+	r, err := s.Run(c, "uk-tech")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Articles == nil || r.Sources != nil || len(r.Articles.Articles) == 0 {
+		t.Fatalf("Unexpected results %+v", r)
+	}
+	if !strings.HasSuffix(r.Articles.Response.URL, "/top-headlines?category=technology&country=gb&pageSize=50") {
+		t.Fatalf("Unexpected URL %v", r.Articles.Response.URL)
+	}
+	r, err = s.Run(c, "english-sources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Sources == nil || r.Articles != nil || len(r.Sources.Source) == 0 {
+		t.Fatalf("Unexpected results %+v", r)
+	}
+	if _, err := s.Run(c, "missing"); err == nil || err.Error() != "No saved search called missing" {
+		t.Fatalf("Unexpected error %v", err)
+	}
+}
+
+func TestSaveSearches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "searches.json")
+	var s SavedSearches
+	if err := s.Add(SavedSearch{Name: "a", Endpoint: EndpointEverything, Parameters: map[string]interface{}{"q": "go", "page": 2, "sources": []string{"bbc-news"}}}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(SavedSearch{Name: "a", Endpoint: EndpointSources}, false); err == nil {
+		t.Fatal("Expected error adding duplicate")
+	}
+	if err := s.Add(SavedSearch{Name: "b", Endpoint: EndpointSources, Parameters: map[string]interface{}{"q": "go"}}, false); err == nil {
+		t.Fatal("Expected error adding invalid search")
+	}
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSearches(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Add(SavedSearch{Name: "a", Endpoint: EndpointSources}, true); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(s.Searches[0].Parameters) != "map[page:2 q:go sources:[bbc-news]]" || len(loaded.Searches) != 1 || loaded.Searches[0].Endpoint != EndpointSources {
+		t.Fatalf("Unexpected searches %+v %+v", s.Searches, loaded.Searches)
+	}
+	if _, err := LoadSearches(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("Expected error loading missing file")
+	}
+}
@@ -0,0 +1,69 @@
+package newsapi
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"strconv"
+)
+
+//redacted replaces secrets wherever they would be shown
+const redacted = "REDACTED"
+
+//Secret holds an API key and hides it when printed, logged or marshalled
+//Use string(s) to get the key itself
+type Secret string
+
+//String returns a placeholder instead of the key, or an empty string when no key is set
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+//GoString stops the key being shown by the %#v verb
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+//MarshalJSON stops the key being included in JSON output
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+//LogValue stops the key being included in slog output
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+//redactURL returns rawURL with any API key in the query string or user info replaced
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return redacted
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	q := u.Query()
+	changed := false
+	for _, k := range redactedKeyParams {
+		if q.Get(k) != "" {
+			q.Set(k, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+//redactError removes any API key from the URL of a *url.Error returned by an http.Client
+func redactError(err error) error {
+	if ue, ok := err.(*url.Error); ok {
+		return &url.Error{Op: ue.Op, URL: redactURL(ue.URL), Err: ue.Err}
+	}
+	return err
+}
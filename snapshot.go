@@ -0,0 +1,171 @@
+package newsapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+//Snapshot is a copy of the top headlines at a point in time, Articles are in the order NewsAPI ranked them
+type Snapshot struct {
+	Taken      time.Time              `json:"taken"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Articles   []Article              `json:"articles"`
+}
+
+//RankedArticle is an article and its position in a Snapshot, counting from one
+type RankedArticle struct {
+	Rank    int     `json:"rank"`
+	Article Article `json:"article"`
+}
+
+//RankChange is an article that moved between Snapshots
+type RankChange struct {
+	From    int     `json:"from"`
+	To      int     `json:"to"`
+	Article Article `json:"article"` //The article as it is in the newer Snapshot
+}
+
+//TitleChange is an article whose title was edited between Snapshots
+type TitleChange struct {
+	Rank    int     `json:"rank"` //Rank in the newer Snapshot
+	From    string  `json:"from"`
+	Article Article `json:"article"` //The article as it is in the newer Snapshot
+}
+
+//SnapshotDiff lists what changed between two Snapshots
+type SnapshotDiff struct {
+	Added    []RankedArticle `json:"added"`
+	Removed  []RankedArticle `json:"removed"`
+	Moved    []RankChange    `json:"moved"`
+	Retitled []TitleChange   `json:"retitled"`
+}
+
+//Empty reports whether nothing changed
+func (d SnapshotDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0 && len(d.Retitled) == 0
+}
+
+//SnapshotTopHeadlines gets the top headlines for p and records them as a Snapshot
+func (c *Client) SnapshotTopHeadlines(p parameters) (Snapshot, error) {
+	r, err := c.GetTopHeadlines(p)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Taken: time.Now().UTC(), Parameters: p, Articles: r.Articles}, nil
+}
+
+//LoadSnapshot reads the snapshot file at path
+func LoadSnapshot(path string) (*Snapshot, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("Invalid snapshot file %v: %v", path, err)
+	}
+	return &s, nil
+}
+
+//Save writes the snapshot to path as indented JSON
+func (s *Snapshot) Save(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+//DiffSnapshots compares two Snapshots, articles are matched with ArticleKey
+//Titles edited on an article with the same URL are reported as Retitled rather than as a removal and an addition
+//Moved lists the fewest articles that explain the new order, the articles in both Snapshots that keep their
+//order relative to each other aren't reported even when an addition, removal or another move shifted them
+func DiffSnapshots(older, newer *Snapshot) SnapshotDiff {
+	var d SnapshotDiff
+	before := rankArticles(older.Articles)
+	after := rankArticles(newer.Articles)
+	kept := keptOrder(commonKeys(older.Articles, after), commonKeys(newer.Articles, before))
+	for i, a := range newer.Articles {
+		k := ArticleKey(a)
+		o, ok := before[k]
+		if !ok {
+			d.Added = append(d.Added, RankedArticle{i + 1, a})
+			continue
+		}
+		if !kept[k] {
+			d.Moved = append(d.Moved, RankChange{o.Rank, i + 1, a})
+		}
+		if o.Article.Title != a.Title {
+			d.Retitled = append(d.Retitled, TitleChange{i + 1, o.Article.Title, a})
+		}
+	}
+	for i, a := range older.Articles {
+		if _, ok := after[ArticleKey(a)]; !ok {
+			d.Removed = append(d.Removed, RankedArticle{i + 1, a})
+		}
+	}
+	return d
+}
+
+//rankArticles indexes articles by ArticleKey, the first of any duplicates is kept
+func rankArticles(articles []Article) map[string]RankedArticle {
+	m := make(map[string]RankedArticle, len(articles))
+	for i, a := range articles {
+		k := ArticleKey(a)
+		if _, ok := m[k]; !ok {
+			m[k] = RankedArticle{i + 1, a}
+		}
+	}
+	return m
+}
+
+//commonKeys returns the keys of the articles that are also in other in order, without duplicates
+func commonKeys(articles []Article, other map[string]RankedArticle) []string {
+	var keys []string
+	seen := make(map[string]bool, len(articles))
+	for _, a := range articles {
+		k := ArticleKey(a)
+		if _, ok := other[k]; ok && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+//keptOrder returns the keys in the longest common subsequence of a and b, the articles that didn't move
+func keptOrder(a, b []string) map[string]bool {
+	//l[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	l := make([][]int, len(a)+1)
+	for i := range l {
+		l[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				l[i][j] = l[i+1][j+1] + 1
+			case l[i+1][j] >= l[i][j+1]:
+				l[i][j] = l[i+1][j]
+			default:
+				l[i][j] = l[i][j+1]
+			}
+		}
+	}
+	kept := make(map[string]bool, l[0][0])
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			kept[a[i]] = true
+			i++
+			j++
+		case l[i+1][j] >= l[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return kept
+}
@@ -0,0 +1,92 @@
+package newsapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	a := Article{Title: "A", URL: "https://a"}
+	b := Article{Title: "B", URL: "https://b"}
+	c := Article{Title: "C", URL: "https://c"}
+	d := Article{Title: "D", URL: "https://d"}
+	b2 := Article{Title: "B edited", URL: "https://b"}
+	noURL := Article{Source: Source{Name: "BBC"}, Title: "No URL"}
+	noURL2 := Article{Source: Source{Name: "BBC"}, Title: "No URL edited"}
+
+	tt := []struct {
+		testName string
+		older    []Article
+		newer    []Article
+		expected SnapshotDiff
+	}{
+		{"Unchanged", []Article{a, b}, []Article{a, b}, SnapshotDiff{}},
+		{"Added and removed", []Article{a, b}, []Article{a, c}, SnapshotDiff{Added: []RankedArticle{{2, c}}, Removed: []RankedArticle{{2, b}}}},
+		{"Moved", []Article{a, b, c}, []Article{c, a, b}, SnapshotDiff{Moved: []RankChange{{3, 1, c}}}},
+		{"Moved to the end", []Article{a, b, c, d}, []Article{b, c, d, a}, SnapshotDiff{Moved: []RankChange{{1, 4, a}}}},
+		{"Swapped", []Article{a, b, c}, []Article{b, a, c}, SnapshotDiff{Moved: []RankChange{{1, 2, a}}}},
+		{"Retitled", []Article{a, b}, []Article{a, b2}, SnapshotDiff{Retitled: []TitleChange{{2, "B", b2}}}},
+		{"Retitled and moved", []Article{a, b, c}, []Article{b2, a, d}, SnapshotDiff{Added: []RankedArticle{{3, d}}, Removed: []RankedArticle{{3, c}}, Moved: []RankChange{{1, 2, a}}, Retitled: []TitleChange{{1, "B", b2}}}},
+		{"Shifted by an addition", []Article{a, b}, []Article{c, a, b}, SnapshotDiff{Added: []RankedArticle{{1, c}}}},
+		{"Shifted by a removal", []Article{a, b, c}, []Article{b, c}, SnapshotDiff{Removed: []RankedArticle{{1, a}}}},
+		{"Retitled and shifted", []Article{a, b}, []Article{b2, d}, SnapshotDiff{Added: []RankedArticle{{2, d}}, Removed: []RankedArticle{{1, a}}, Retitled: []TitleChange{{1, "B", b2}}}},
+		{"No URL matched by title", []Article{noURL}, []Article{noURL2}, SnapshotDiff{Added: []RankedArticle{{1, noURL2}}, Removed: []RankedArticle{{1, noURL}}}},
+		{"Empty", nil, []Article{a}, SnapshotDiff{Added: []RankedArticle{{1, a}}}},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			d := DiffSnapshots(&Snapshot{Articles: v.older}, &Snapshot{Articles: v.newer})
+			if !reflect.DeepEqual(d, v.expected) {
+				t.Fatalf("Expected %+v got %+v", v.expected, d)
+			}
+			if d.Empty() != reflect.DeepEqual(v.expected, SnapshotDiff{}) {
+				t.Fatalf("Unexpected Empty %v", d.Empty())
+			}
+		})
+	}
+}
+
+func TestSnapshotTopHeadlines(t *testing.T) {
+	d, err := ioutil.ReadFile("testdata/topheadlines_sucess.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(d)
+	}))
+	defer testServer.Close()
+
+	c := New("abc", WithBaseURL(testServer.URL))
+	start := time.Now()
+	s, err := c.SnapshotTopHeadlines(parameters{"country": "gb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Articles) != 20 || s.Parameters["country"] != "gb" || s.Taken.Before(start.Add(-time.Second)) {
+		t.Fatalf("Unexpected snapshot %+v", s)
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Taken.Equal(s.Taken) || !reflect.DeepEqual(loaded.Articles, s.Articles) || !DiffSnapshots(&s, loaded).Empty() {
+		t.Fatalf("Expected loaded snapshot to match got %+v", loaded)
+	}
+
+	if _, err := c.SnapshotTopHeadlines(parameters{"country": "xx"}); err == nil {
+		t.Fatal("Expected error for invalid parameters")
+	}
+	ioutil.WriteFile(path, []byte("nope"), 0644)
+	if _, err := LoadSnapshot(path); err == nil {
+		t.Fatal("Expected error for invalid snapshot file")
+	}
+}
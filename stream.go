@@ -0,0 +1,206 @@
+package newsapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+//ErrBodyTooLarge is returned when a reply is bigger than the Client's maximum body size
+var ErrBodyTooLarge = errors.New("NewsAPI response body exceeds maximum size")
+
+//WithMaxBodySize makes the Client fail with ErrBodyTooLarge when a reply body is larger than n bytes
+func WithMaxBodySize(n int64) option {
+	return func(c *Client) {
+		c.maxBodySize = n
+	}
+}
+
+//limitBody wraps r so reading more than the Client's maximum body size returns ErrBodyTooLarge
+func (c *Client) limitBody(r io.Reader) io.Reader {
+	if c.maxBodySize <= 0 {
+		return r
+	}
+	return &limitedReader{r: r, n: c.maxBodySize}
+}
+
+//limitedReader is an io.LimitedReader that errors instead of returning io.EOF at the limit
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		//Only fail if there is really more data to read
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+//StreamTopHeadlines works like GetTopHeadlines but calls fn with each article as it is decoded from the reply
+//The returned ArticleResults has no Articles, returning an error from fn stops the stream and returns that error
+func (c *Client) StreamTopHeadlines(p parameters, fn func(Article) error) (ArticleResults, error) {
+	return c.StreamTopHeadlinesContext(context.Background(), p, fn)
+}
+
+//StreamTopHeadlinesContext works like StreamTopHeadlines, the request is cancelled when ctx is done
+func (c *Client) StreamTopHeadlinesContext(ctx context.Context, p parameters, fn func(Article) error) (ArticleResults, error) {
+	var o ArticleResults
+	u, err := c.requestURL(EndpointTopHeadlines, p)
+	if err != nil {
+		return o, err
+	}
+	return c.stream(ctx, EndpointTopHeadlines, u, fn)
+}
+
+//StreamEverything works like GetEverything but calls fn with each article as it is decoded from the reply
+//The returned ArticleResults has no Articles, returning an error from fn stops the stream and returns that error
+func (c *Client) StreamEverything(p parameters, fn func(Article) error) (ArticleResults, error) {
+	return c.StreamEverythingContext(context.Background(), p, fn)
+}
+
+//StreamEverythingContext works like StreamEverything, the request is cancelled when ctx is done
+func (c *Client) StreamEverythingContext(ctx context.Context, p parameters, fn func(Article) error) (ArticleResults, error) {
+	var o ArticleResults
+	u, err := c.requestURL(EndpointEverything, p)
+	if err != nil {
+		return o, err
+	}
+	return c.stream(ctx, EndpointEverything, u, fn)
+}
+
+//stream requests endpoint and decodes the articles in the reply one at a time
+func (c *Client) stream(ctx context.Context, e Endpoint, endpoint string, fn func(Article) error) (ArticleResults, error) {
+	var o ArticleResults
+	var n int
+	count := func(a Article) error {
+		n++
+		return fn(a)
+	}
+	if c.dryRun {
+		return o, c.dryRunError(endpoint)
+	}
+	start := time.Now()
+	r, err := c.withKey(func(key Secret) (*Response, error) {
+		if err := c.takeQuota(); err != nil {
+			return nil, err
+		}
+		res, r, err := c.open(ctx, endpoint, key)
+		if err != nil {
+			return r, err
+		}
+		defer closeBody(res.Body)
+		err = c.decodeArticles(c.limitBody(res.Body), &o, count)
+		r.Latency = time.Since(start)
+		return r, err
+	})
+	o.Response = r
+	c.observe(ctx, Event{Endpoint: e, Start: start, Response: r, Results: n, Err: err}, endpoint)
+	return o, err
+}
+
+//decodeArticles reads an ArticleResults object from r token by token
+//Each article is passed to fn instead of being stored in o
+func (c *Client) decodeArticles(r io.Reader, o *ArticleResults, fn func(Article) error) error {
+	d := json.NewDecoder(r)
+	if c.strict {
+		d.DisallowUnknownFields()
+	}
+	if err := expectDelim(d, '{'); err != nil {
+		return streamError(err)
+	}
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return streamError(err)
+		}
+		switch t {
+		case "status":
+			err = d.Decode(&o.Status)
+		case "totalResults":
+			err = d.Decode(&o.TotalResults)
+		case "articles":
+			err = decodeArray(d, fn)
+		default:
+			if c.strict {
+				return &DecodeError{Err: fmt.Errorf("json: unknown field %q", t)}
+			}
+			var skip json.RawMessage
+			err = d.Decode(&skip)
+		}
+		if err != nil {
+			return streamError(err)
+		}
+	}
+	return streamError(expectDelim(d, '}'))
+}
+
+//streamError converts an error from decoding a stream into the error returned to the caller
+//Callback errors and ErrBodyTooLarge are passed through, anything else is a DecodeError
+func streamError(err error) error {
+	var cbErr callbackError
+	var decErr *DecodeError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &cbErr):
+		return cbErr.err
+	case errors.Is(err, ErrBodyTooLarge):
+		return ErrBodyTooLarge
+	case errors.As(err, &decErr):
+		return err
+	}
+	return &DecodeError{Err: err}
+}
+
+//callbackError wraps errors returned by a stream callback so they aren't reported as decode failures
+type callbackError struct {
+	err error
+}
+
+func (e callbackError) Error() string {
+	return e.err.Error()
+}
+
+//decodeArray decodes a JSON array of articles from d passing each one to fn
+func decodeArray(d *json.Decoder, fn func(Article) error) error {
+	if err := expectDelim(d, '['); err != nil {
+		return err
+	}
+	for d.More() {
+		var a Article
+		if err := d.Decode(&a); err != nil {
+			return err
+		}
+		if err := fn(a); err != nil {
+			return callbackError{err}
+		}
+	}
+	return expectDelim(d, ']')
+}
+
+//expectDelim reads the next token from d and checks it is the delimiter want
+func expectDelim(d *json.Decoder, want json.Delim) error {
+	t, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if t != want {
+		return fmt.Errorf("Expected '%v' got '%v'", want, t)
+	}
+	return nil
+}
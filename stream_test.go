@@ -0,0 +1,212 @@
+package newsapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamEverything(t *testing.T) {
+	sData, err := ioutil.ReadFile("testdata/everything_sucess.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fData, err := ioutil.ReadFile("testdata/everything_failure.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testServer := fakeServer(sData, fData)
+	defer testServer.Close()
+
+	var expected ArticleResults
+	if err := json.Unmarshal(sData, &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New("TestAPIKey")
+	c.APIUrl = testServer.URL
+	var got []Article
+	o, err := c.StreamEverything(parameters{"q": "bitcoin"}, func(a Article) error {
+		got = append(got, a)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != expected.Status || o.TotalResults != expected.TotalResults {
+		t.Fatalf("Expected %v %v got %v %v", expected.Status, expected.TotalResults, o.Status, o.TotalResults)
+	}
+	if len(got) != len(expected.Articles) {
+		t.Fatalf("Expected %d articles got %d", len(expected.Articles), len(got))
+	}
+	for i := range got {
+		if got[i] != expected.Articles[i] {
+			t.Fatalf("Article %d doesn't match, expected %+v got %+v", i, expected.Articles[i], got[i])
+		}
+	}
+	if o.Response == nil || o.Response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected response metadata got %+v", o.Response)
+	}
+
+	c.APIUrl = testServer.URL + "/failure/"
+	if _, err := c.StreamEverything(parameters{}, func(Article) error { return nil }); err == nil {
+		t.Fatal("Expected error from API got nil")
+	}
+}
+
+func TestStreamTopHeadlinesStopsOnCallbackError(t *testing.T) {
+	sData, err := ioutil.ReadFile("testdata/topheadlines_sucess.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testServer := fakeServer(sData, nil)
+	defer testServer.Close()
+
+	c := New("TestAPIKey")
+	c.APIUrl = testServer.URL
+	stop := errors.New("stop")
+	var n int
+	_, err = c.StreamTopHeadlines(parameters{}, func(Article) error {
+		n++
+		if n == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("Expected '%v' got '%v'", stop, err)
+	}
+	if n != 2 {
+		t.Fatalf("Expected 2 articles before stopping got %d", n)
+	}
+}
+
+func TestDecodeArticles(t *testing.T) {
+	tt := []struct {
+		testName      string
+		strict        bool
+		body          string
+		expectedCount int
+		expectedError error
+	}{
+		{"Valid", false, `{"status":"ok","totalResults":2,"articles":[{"title":"a"},{"title":"b"}]}`, 2, nil},
+		{"Unknown field lenient", false, `{"status":"ok","extra":{"a":[1,2]},"articles":[{"title":"a","new":1}]}`, 1, nil},
+		{"Unknown top level field strict", true, `{"status":"ok","extra":1,"articles":[]}`, 0, &DecodeError{}},
+		{"Unknown article field strict", true, `{"status":"ok","articles":[{"title":"a","new":1}]}`, 0, &DecodeError{}},
+		{"Not an object", false, `[]`, 0, &DecodeError{}},
+		{"Truncated", false, `{"status":"ok","articles":[{"title":"a"},`, 1, &DecodeError{}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New("TestAPIKey")
+			c.strict = v.strict
+			var o ArticleResults
+			var n int
+			err := c.decodeArticles(strings.NewReader(v.body), &o, func(Article) error {
+				n++
+				return nil
+			})
+			if v.expectedError == nil && err != nil {
+				t.Fatalf("Unexpected error - %v", err)
+			}
+			if _, ok := err.(*DecodeError); v.expectedError != nil && !ok {
+				t.Fatalf("Expected DecodeError got %v", err)
+			}
+			if n != v.expectedCount {
+				t.Fatalf("Expected %d articles got %d", v.expectedCount, n)
+			}
+		})
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	sData, err := ioutil.ReadFile("testdata/everything_sucess.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testServer := fakeServer(sData, nil)
+	defer testServer.Close()
+
+	tt := []struct {
+		testName      string
+		max           int64
+		expectedError error
+	}{
+		{"No limit", 0, nil},
+		{"Exact size", int64(len(sData)), nil},
+		{"Too small", int64(len(sData)) - 2, ErrBodyTooLarge},
+	}
+	for _, v := range tt {
+		t.Run(v.testName, func(t *testing.T) {
+			c := New("TestAPIKey", WithMaxBodySize(v.max))
+			c.APIUrl = testServer.URL
+			if _, err := c.GetEverything(parameters{}); err != v.expectedError {
+				t.Fatalf("Get expected '%v' got '%v'", v.expectedError, err)
+			}
+			if _, err := c.StreamEverything(parameters{}, func(Article) error { return nil }); err != v.expectedError {
+				t.Fatalf("Stream expected '%v' got '%v'", v.expectedError, err)
+			}
+		})
+	}
+}
+
+//benchmarkServer serves a page of 100 articles built from the everything fixture
+func benchmarkServer(b *testing.B) *httptest.Server {
+	d, err := ioutil.ReadFile("testdata/everything_sucess.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var o ArticleResults
+	if err := json.Unmarshal(d, &o); err != nil {
+		b.Fatal(err)
+	}
+	page := ArticleResults{Status: "ok", TotalResults: 100}
+	for len(page.Articles) < 100 {
+		page.Articles = append(page.Articles, o.Articles...)
+	}
+	page.Articles = page.Articles[:100]
+	body, err := json.Marshal(page)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+}
+
+func BenchmarkGetEverything(b *testing.B) {
+	testServer := benchmarkServer(b)
+	defer testServer.Close()
+	c := New("TestAPIKey")
+	c.APIUrl = testServer.URL
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		o, err := c.GetEverything(parameters{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		for range o.Articles {
+		}
+	}
+}
+
+func BenchmarkStreamEverything(b *testing.B) {
+	testServer := benchmarkServer(b)
+	defer testServer.Close()
+	c := New("TestAPIKey")
+	c.APIUrl = testServer.URL
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := c.StreamEverything(parameters{}, func(Article) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}